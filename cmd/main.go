@@ -5,6 +5,8 @@ import (
 	"flag"
 	"os"
 
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
 	"github.com/singhmeghna79/homework-object-storage/pkg/server"
 
 	"go.uber.org/zap"
@@ -13,16 +15,74 @@ import (
 
 func main() {
 	// Parse command line flags
+	storageConfig := objectstorage.DefaultConfig()
 	serverPort := flag.String("port", "3000", "HTTP server port")
-	storageType := flag.String("storageType", "minio", "Object Storage Type")
+	storageType := flag.String("storageType", "minio", "Object Storage Type (minio, fs, memory, s3 or tiered)")
+	flag.StringVar(&storageConfig.FilesystemRoot, "fsRoot", storageConfig.FilesystemRoot, "Directory the fs storage type keeps its objects in")
+	flag.StringVar(&storageConfig.S3.Endpoint, "s3Endpoint", "", "Host and port of the S3 API for the s3 storage type, e.g. s3.eu-west-1.amazonaws.com")
+	flag.StringVar(&storageConfig.S3.Region, "s3Region", "", "Region of the S3 bucket (default: looked up from the service)")
+	flag.StringVar(&storageConfig.S3.Bucket, "s3Bucket", "", "S3 bucket the objects are stored in")
+	flag.StringVar(&storageConfig.S3.AccessKey, "s3AccessKey", "", "S3 access key (default: the AWS_ACCESS_KEY_ID environment variable)")
+	flag.StringVar(&storageConfig.S3.SecretKey, "s3SecretKey", "", "S3 secret key (default: the AWS_SECRET_ACCESS_KEY environment variable)")
+	flag.BoolVar(&storageConfig.S3.PathStyle, "s3PathStyle", false, "Address the S3 bucket in the path instead of the host name")
+	flag.BoolVar(&storageConfig.S3.TLS, "s3TLS", storageConfig.S3.TLS, "Connect to the S3 API with HTTPS")
+	flag.StringVar(&storageConfig.S3.CAFile, "s3CAFile", "", "PEM file with additional certificates trusted for the S3 API")
+	flag.BoolVar(&storageConfig.S3.InsecureSkipVerify, "s3InsecureSkipVerify", false, "Accept any certificate of the S3 API, for testing only")
+	flag.StringVar(&storageConfig.Tiered.HotType, "tierHot", storageConfig.Tiered.HotType, "Storage type new objects are written to by the tiered storage type")
+	flag.StringVar(&storageConfig.Tiered.ColdType, "tierCold", storageConfig.Tiered.ColdType, "Storage type the tiered storage type demotes objects to")
+	flag.StringVar(&storageConfig.Tiered.ColdFilesystemRoot, "tierColdFsRoot", "", "Directory of a cold fs tier (default: the fsRoot directory)")
+	flag.StringVar(&storageConfig.Tiered.ColdS3Bucket, "tierColdS3Bucket", "", "S3 bucket of a cold s3 tier (default: the s3Bucket bucket)")
+	flag.DurationVar(&storageConfig.Tiered.MaxAge, "tierMaxAge", storageConfig.Tiered.MaxAge, "Demote objects last written longer ago (0 disables it)")
+	flag.DurationVar(&storageConfig.Tiered.MaxIdle, "tierMaxIdle", storageConfig.Tiered.MaxIdle, "Demote objects not read for longer (0 disables it)")
+	flag.Int64Var(&storageConfig.Tiered.MinSize, "tierMinSize", storageConfig.Tiered.MinSize, "Keep objects smaller than this many bytes in the hot tier")
+	flag.DurationVar(&storageConfig.Tiered.Interval, "tierInterval", storageConfig.Tiered.Interval, "Time between demotion passes (0 disables them)")
+	flag.BoolVar(&storageConfig.Tiered.Promote, "tierPromote", false, "Move objects read from the cold tier back to the hot tier")
+	flag.StringVar(&storageConfig.EncryptionKeyFile, "encryptionKeyFile", "", "YAML file holding the master keys objects are encrypted with (default: no encryption)")
+	flag.BoolVar(&storageConfig.EncryptionRotate, "encryptionRotate", false, "Wrap the data keys of all objects with the current master key at startup")
+	flag.Int64Var(&storageConfig.MemoryMaxBytes, "memoryMaxBytes", 0, "Bytes of objects the memory storage type holds before evicting the least recently used (0 is unlimited)")
+	flag.IntVar(&storageConfig.ReplicationFactor, "replicas", storageConfig.ReplicationFactor, "Number of nodes every object is stored on")
+	flag.IntVar(&storageConfig.WriteQuorum, "writeQuorum", storageConfig.WriteQuorum, "Number of replicas that must acknowledge a write")
+	flag.IntVar(&storageConfig.ReadQuorum, "readQuorum", storageConfig.ReadQuorum, "Number of replicas that must agree on a read")
+	flag.StringVar(&storageConfig.Placement, "placement", storageConfig.Placement, "Placement strategy mapping objects to nodes (ring or rendezvous)")
+	flag.IntVar(&storageConfig.VirtualNodes, "virtualNodes", storageConfig.VirtualNodes, "Number of points every node owns on the consistent hash ring")
+	flag.Float64Var(&storageConfig.RebalanceObjectsPerSecond, "rebalanceObjectsPerSecond", 0, "Maximum objects migrated per second by the rebalancer (0 is unlimited)")
+	flag.Int64Var(&storageConfig.RebalanceBytesPerSecond, "rebalanceBytesPerSecond", 0, "Maximum bytes migrated per second by the rebalancer (0 is unlimited)")
+	flag.BoolVar(&storageConfig.RebalanceOnChange, "rebalanceOnChange", false, "Start a rebalance whenever nodes join or leave")
+	flag.BoolVar(&storageConfig.ReadRepair, "readRepair", storageConfig.ReadRepair, "Copy objects found outside their replicas back to the replicas on read")
+	flag.DurationVar(&storageConfig.HealthCheckInterval, "healthInterval", storageConfig.HealthCheckInterval, "Time between health checks of every node (0 disables them)")
+	flag.DurationVar(&storageConfig.HealthCheckTimeout, "healthTimeout", storageConfig.HealthCheckTimeout, "Timeout of a single node health check")
+	flag.IntVar(&storageConfig.UnhealthyThreshold, "unhealthyThreshold", storageConfig.UnhealthyThreshold, "Consecutive failed health checks after which a node is ejected")
+	flag.IntVar(&storageConfig.HealthyThreshold, "healthyThreshold", storageConfig.HealthyThreshold, "Consecutive successful health checks after which a node is re-admitted")
+	flag.IntVar(&storageConfig.BreakerFailureThreshold, "breakerFailures", storageConfig.BreakerFailureThreshold, "Consecutive failures after which requests to a node fail fast (0 disables the circuit breakers)")
+	flag.DurationVar(&storageConfig.BreakerOpenTimeout, "breakerOpenTimeout", storageConfig.BreakerOpenTimeout, "How long requests to a failing node fail fast before it is tried again")
+	flag.IntVar(&storageConfig.BreakerHalfOpenRequests, "breakerHalfOpenRequests", storageConfig.BreakerHalfOpenRequests, "Number of requests let through to try a failing node again")
+	flag.StringVar(&storageConfig.Discovery, "discovery", storageConfig.Discovery, "Mechanism finding the Minio nodes (docker, file or dns)")
+	flag.StringVar(&storageConfig.NodesFile, "nodesFile", "", "YAML or JSON file listing the Minio nodes for file discovery")
+	flag.StringVar(&storageConfig.DNS.Name, "dnsName", "", "SRV record or host name listing the Minio nodes for dns discovery, e.g. _minio._tcp.storage.local")
+	flag.StringVar(&storageConfig.DNS.Port, "dnsPort", storageConfig.DNS.Port, "Minio API port of nodes found through A records")
+	flag.StringVar(&storageConfig.DNS.Server, "dnsServer", "", "DNS server to ask for the records (default: the system resolver)")
+	flag.DurationVar(&storageConfig.DNS.RefreshInterval, "dnsRefresh", storageConfig.DNS.RefreshInterval, "How often the DNS records are resolved again")
+	flag.StringVar(&storageConfig.DNS.SecretsFile, "dnsSecretsFile", "", "YAML file holding the credentials of the nodes found through DNS")
+	flag.StringVar(&storageConfig.Docker.NamePattern, "nodeNamePattern", storageConfig.Docker.NamePattern, "Regular expression matching the names of Minio node containers")
+	flag.Func("nodeLabels", "Comma separated labels Minio node containers must carry, e.g. objstore.role=node", func(value string) error {
+		labels, err := docker.ParseLabels(value)
+		storageConfig.Docker.Labels = labels
+		return err
+	})
+	flag.StringVar(&storageConfig.Docker.Network, "dockerNetwork", "", "Docker network the Minio nodes are reached on (default: the network of each container)")
+	flag.StringVar(&storageConfig.Docker.APIPort, "nodeApiPort", storageConfig.Docker.APIPort, "Port of the Minio S3 API inside the node containers")
 	flag.Parse()
 
 	// Setup logger
 	logger := setUpLogger()
 	defer logger.Sync()
 
+	if err := storageConfig.Validate(); err != nil {
+		logger.Fatal("Invalid storage configuration", zap.Error(err))
+	}
+
 	// Initialize and run server
-	srv := server.New(*serverPort, *storageType, logger)
+	srv := server.New(*serverPort, *storageType, storageConfig, logger)
 	srv.Run()
 
 	os.Exit(0)
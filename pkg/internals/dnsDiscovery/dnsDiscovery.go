@@ -0,0 +1,255 @@
+package dnsDiscovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/placement"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultRefreshInterval is how often the records are resolved again
+	DefaultRefreshInterval = 30 * time.Second
+	defaultPort            = "9000"
+	lookupTimeout          = 5 * time.Second
+)
+
+// Config describes the records listing the nodes
+type Config struct {
+	// Name is an SRV record such as _minio._tcp.storage.local, or a host name whose A and AAAA
+	// records are the nodes
+	Name string
+	// Port is the Minio API port of nodes found through A and AAAA records
+	Port string
+	// Server is the address of the DNS server to ask, empty uses the system resolver
+	Server string
+	// RefreshInterval is how often the records are resolved again. The resolver does not report
+	// record TTLs, so the interval is fixed and should not exceed the TTL of the records.
+	RefreshInterval time.Duration
+	// SecretsFile is the YAML file holding the node credentials
+	SecretsFile string
+}
+
+// DefaultConfig returns the settings used when only the name and secrets file are given
+func DefaultConfig() Config {
+	return Config{Port: defaultPort, RefreshInterval: DefaultRefreshInterval}
+}
+
+// Validate checks that the records and credentials can be looked up
+func (c Config) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("dns discovery needs a record name")
+	}
+	if c.SecretsFile == "" {
+		return fmt.Errorf("dns discovery needs a secrets file")
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid node API port %q", c.Port)
+	}
+	if c.RefreshInterval <= 0 {
+		return fmt.Errorf("dns refresh interval must be positive")
+	}
+	return nil
+}
+
+// credentials are the keys of a node
+type credentials struct {
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+}
+
+// secretsFile holds the default credentials and the credentials of individual hosts
+type secretsFile struct {
+	credentials `yaml:",inline"`
+	Nodes       map[string]credentials `yaml:"nodes"`
+}
+
+// resolver is the part of net.Resolver used for discovery
+type resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// DNSDiscovery resolves the nodes from DNS records and resolves them again periodically
+type DNSDiscovery struct {
+	config   Config
+	resolver resolver
+	logger   *zap.Logger
+}
+
+var _ docker.Discovery = (*DNSDiscovery)(nil)
+
+// New creates a discovery for the records described by config
+func New(config Config, logger *zap.Logger) (*DNSDiscovery, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := net.DefaultResolver
+	if config.Server != "" {
+		dialer := net.Dialer{Timeout: lookupTimeout}
+		r = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, config.Server)
+			},
+		}
+	}
+	return &DNSDiscovery{config: config, resolver: r, logger: logger}, nil
+}
+
+// DiscoverMinioNodes resolves the records and reads the credentials of the nodes. Targets whose
+// address cannot be resolved or whose weight exceeds placement.MaxWeight are logged and left out,
+// the lookup only fails when no target is left.
+func (d *DNSDiscovery) DiscoverMinioNodes(ctx context.Context) ([]docker.MinioNode, error) {
+	secrets, err := readSecrets(d.config.SecretsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	targets, err := d.lookupTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]docker.MinioNode, 0, len(targets))
+	var lastErr error
+	for _, target := range targets {
+		if target.weight > placement.MaxWeight {
+			lastErr = fmt.Errorf("node %s: SRV weight %d exceeds the maximum weight %d", target.host, target.weight, placement.MaxWeight)
+			d.logger.Warn("Skipping node with too large a weight", zap.String("name", d.config.Name), zap.String("node_name", target.host), zap.Int("weight", target.weight))
+			continue
+		}
+
+		// Nodes are reached by address so the system resolver does not need to know the records
+		addresses, err := d.resolver.LookupHost(ctx, target.host)
+		if err == nil && len(addresses) == 0 {
+			err = fmt.Errorf("no addresses")
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("failed to resolve node %s: %w", target.host, err)
+			d.logger.Warn("Skipping node that cannot be resolved", zap.String("name", d.config.Name), zap.String("node_name", target.host), zap.Error(err))
+			continue
+		}
+		sort.Strings(addresses)
+
+		creds, ok := secrets.Nodes[target.host]
+		if !ok {
+			creds = secrets.credentials
+		}
+		nodes = append(nodes, docker.MinioNode{
+			ID:        net.JoinHostPort(target.host, target.port),
+			Name:      target.host,
+			IPAddress: addresses[0],
+			Port:      target.port,
+			AccessKey: creds.AccessKey,
+			SecretKey: creds.SecretKey,
+			Weight:    target.weight,
+		})
+	}
+
+	if len(nodes) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
+// Watch resolves the records every refresh interval and calls onChange with the nodes of the first
+// lookup and whenever they changed.
+// Failed lookups are logged and keep the previous nodes.
+func (d *DNSDiscovery) Watch(ctx context.Context, logger *zap.Logger, onChange func([]docker.MinioNode)) {
+	// The first lookup is always reported, in case the nodes could not be resolved at startup
+	var last []docker.MinioNode
+
+	ticker := time.NewTicker(d.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		nodes, err := d.DiscoverMinioNodes(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("Failed to resolve Minio nodes", zap.String("name", d.config.Name), zap.Error(err))
+			}
+			continue
+		}
+		if reflect.DeepEqual(nodes, last) {
+			continue
+		}
+		last = nodes
+
+		logger.Info("DNS records changed", zap.String("name", d.config.Name), zap.Int("minio nodes", len(nodes)))
+		onChange(nodes)
+	}
+}
+
+// target is a host serving the Minio API
+type target struct {
+	host   string
+	port   string
+	weight int
+}
+
+// lookupTargets resolves the SRV record, or uses the name itself as the only host when it is not one
+func (d *DNSDiscovery) lookupTargets(ctx context.Context) ([]target, error) {
+	if !strings.HasPrefix(d.config.Name, "_") {
+		addresses, err := d.resolver.LookupHost(ctx, d.config.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", d.config.Name, err)
+		}
+		// Every address of the name is a node
+		targets := make([]target, 0, len(addresses))
+		for _, address := range addresses {
+			targets = append(targets, target{host: address, port: d.config.Port, weight: 1})
+		}
+		return targets, nil
+	}
+
+	_, records, err := d.resolver.LookupSRV(ctx, "", "", d.config.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", d.config.Name, err)
+	}
+	targets := make([]target, 0, len(records))
+	for _, record := range records {
+		targets = append(targets, target{
+			host: strings.TrimSuffix(record.Target, "."),
+			port: strconv.Itoa(int(record.Port)),
+			// The SRV weight is the node's share of the objects, records without one count as 1
+			weight: max(int(record.Weight), 1),
+		})
+	}
+	return targets, nil
+}
+
+// readSecrets reads the credentials, the file is read on every lookup so rotated keys are picked up
+func readSecrets(path string) (secretsFile, error) {
+	var secrets secretsFile
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return secrets, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	if err := yaml.Unmarshal(content, &secrets); err != nil {
+		return secrets, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	return secrets, nil
+}
@@ -0,0 +1,239 @@
+package dnsDiscovery
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// testServer is an in-process DNS server answering SRV and A queries
+type testServer struct {
+	conn  net.PacketConn
+	mutex sync.Mutex
+	srv   map[string][]dnsmessage.SRVResource
+	a     map[string][]dnsmessage.AResource
+}
+
+func newTestServer(t *testing.T) *testServer {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := &testServer{conn: conn, srv: map[string][]dnsmessage.SRVResource{}, a: map[string][]dnsmessage.AResource{}}
+	t.Cleanup(func() { conn.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *testServer) setSRV(name string, records ...dnsmessage.SRVResource) {
+	s.mutex.Lock()
+	s.srv[name] = records
+	s.mutex.Unlock()
+}
+
+func (s *testServer) setA(name string, ips ...[4]byte) {
+	s.mutex.Lock()
+	s.a[name] = nil
+	for _, ip := range ips {
+		s.a[name] = append(s.a[name], dnsmessage.AResource{A: ip})
+	}
+	s.mutex.Unlock()
+}
+
+func (s *testServer) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var request dnsmessage.Message
+		if err := request.Unpack(buf[:n]); err != nil || len(request.Questions) != 1 {
+			continue
+		}
+		if response, err := s.answer(request); err == nil {
+			s.conn.WriteTo(response, addr)
+		}
+	}
+}
+
+func (s *testServer) answer(request dnsmessage.Message) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	question := request.Questions[0]
+	name := question.Name.String()
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: request.ID, Response: true, Authoritative: true})
+	builder.EnableCompression()
+	_, knownSRV := s.srv[name]
+	_, knownA := s.a[name]
+	if !knownSRV && !knownA {
+		builder = dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: request.ID, Response: true, RCode: dnsmessage.RCodeNameError})
+	}
+	builder.StartQuestions()
+	builder.Question(question)
+	builder.StartAnswers()
+
+	header := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 1}
+	switch question.Type {
+	case dnsmessage.TypeSRV:
+		for _, record := range s.srv[name] {
+			builder.SRVResource(header, record)
+		}
+	case dnsmessage.TypeA:
+		for _, record := range s.a[name] {
+			builder.AResource(header, record)
+		}
+	}
+	return builder.Finish()
+}
+
+func writeSecrets(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "secrets.yaml")
+	secrets := `
+access_key: default
+secret_key: defaultsecret
+nodes:
+  storage-2.local:
+    access_key: maglev
+    secret_key: baconpapaya
+`
+	assert.NoError(t, os.WriteFile(path, []byte(secrets), 0o600))
+	return path
+}
+
+func newTestDiscovery(t *testing.T, server *testServer, name string) *DNSDiscovery {
+	config := DefaultConfig()
+	config.Name = name
+	config.Server = server.conn.LocalAddr().String()
+	config.SecretsFile = writeSecrets(t)
+	config.RefreshInterval = 10 * time.Millisecond
+	discovery, err := New(config, zap.NewNop())
+	assert.NoError(t, err)
+	return discovery
+}
+
+func TestDiscoverSRV(t *testing.T) {
+	server := newTestServer(t)
+	server.setSRV("_minio._tcp.storage.local.",
+		dnsmessage.SRVResource{Target: dnsmessage.MustNewName("storage-1.local."), Port: 9000},
+		dnsmessage.SRVResource{Target: dnsmessage.MustNewName("storage-2.local."), Port: 9100, Weight: 3},
+	)
+	server.setA("storage-1.local.", [4]byte{10, 0, 0, 1})
+	server.setA("storage-2.local.", [4]byte{10, 0, 0, 2})
+
+	nodes, err := newTestDiscovery(t, server, "_minio._tcp.storage.local").DiscoverMinioNodes(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []docker.MinioNode{
+		{ID: "storage-1.local:9000", Name: "storage-1.local", IPAddress: "10.0.0.1", Port: "9000", AccessKey: "default", SecretKey: "defaultsecret", Weight: 1},
+		{ID: "storage-2.local:9100", Name: "storage-2.local", IPAddress: "10.0.0.2", Port: "9100", AccessKey: "maglev", SecretKey: "baconpapaya", Weight: 3},
+	}, nodes)
+}
+
+func TestDiscoverSkipsUnresolvableTargets(t *testing.T) {
+	server := newTestServer(t)
+	server.setSRV("_minio._tcp.storage.local.",
+		dnsmessage.SRVResource{Target: dnsmessage.MustNewName("storage-1.local."), Port: 9000},
+		dnsmessage.SRVResource{Target: dnsmessage.MustNewName("missing.local."), Port: 9000},
+	)
+	server.setA("storage-1.local.", [4]byte{10, 0, 0, 1})
+	discovery := newTestDiscovery(t, server, "_minio._tcp.storage.local")
+
+	// The other nodes are still reported
+	nodes, err := discovery.DiscoverMinioNodes(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+	assert.Equal(t, "storage-1.local:9000", nodes[0].ID)
+
+	// Without any node left the lookup fails, so the previous nodes are kept
+	server.setSRV("_minio._tcp.storage.local.", dnsmessage.SRVResource{Target: dnsmessage.MustNewName("missing.local."), Port: 9000})
+	_, err = discovery.DiscoverMinioNodes(context.Background())
+	assert.ErrorContains(t, err, "failed to resolve node missing.local")
+}
+
+func TestDiscoverSkipsTargetsWithTooLargeWeights(t *testing.T) {
+	server := newTestServer(t)
+	server.setSRV("_minio._tcp.storage.local.",
+		dnsmessage.SRVResource{Target: dnsmessage.MustNewName("storage-1.local."), Port: 9000, Weight: 100},
+		dnsmessage.SRVResource{Target: dnsmessage.MustNewName("storage-2.local."), Port: 9000, Weight: 65535},
+	)
+	server.setA("storage-1.local.", [4]byte{10, 0, 0, 1})
+	server.setA("storage-2.local.", [4]byte{10, 0, 0, 2})
+	discovery := newTestDiscovery(t, server, "_minio._tcp.storage.local")
+
+	nodes, err := discovery.DiscoverMinioNodes(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+	assert.Equal(t, "storage-1.local:9000", nodes[0].ID)
+
+	server.setSRV("_minio._tcp.storage.local.", dnsmessage.SRVResource{Target: dnsmessage.MustNewName("storage-2.local."), Port: 9000, Weight: 65535})
+	_, err = discovery.DiscoverMinioNodes(context.Background())
+	assert.EqualError(t, err, "node storage-2.local: SRV weight 65535 exceeds the maximum weight 100")
+}
+
+func TestDiscoverA(t *testing.T) {
+	server := newTestServer(t)
+	server.setA("storage.local.", [4]byte{10, 0, 0, 2}, [4]byte{10, 0, 0, 1})
+
+	nodes, err := newTestDiscovery(t, server, "storage.local").DiscoverMinioNodes(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 2)
+	assert.Equal(t, "10.0.0.1:9000", nodes[0].ID)
+	assert.Equal(t, "10.0.0.2:9000", nodes[1].ID)
+	assert.Equal(t, "default", nodes[0].AccessKey)
+
+	_, err = newTestDiscovery(t, server, "missing.local").DiscoverMinioNodes(context.Background())
+	assert.ErrorContains(t, err, "failed to resolve missing.local")
+}
+
+func TestWatch(t *testing.T) {
+	server := newTestServer(t)
+	server.setSRV("_minio._tcp.storage.local.", dnsmessage.SRVResource{Target: dnsmessage.MustNewName("storage-1.local."), Port: 9000})
+	server.setA("storage-1.local.", [4]byte{10, 0, 0, 1})
+	server.setA("storage-2.local.", [4]byte{10, 0, 0, 2})
+
+	discovery := newTestDiscovery(t, server, "_minio._tcp.storage.local")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan []docker.MinioNode, 10)
+	go discovery.Watch(ctx, zap.NewNop(), func(nodes []docker.MinioNode) { changes <- nodes })
+
+	// The first lookup is reported, unchanged records are not
+	select {
+	case nodes := <-changes:
+		assert.Len(t, nodes, 1)
+	case <-time.After(2 * time.Second):
+		t.Fatal("first lookup was not reported")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, changes)
+
+	server.setSRV("_minio._tcp.storage.local.",
+		dnsmessage.SRVResource{Target: dnsmessage.MustNewName("storage-1.local."), Port: 9000},
+		dnsmessage.SRVResource{Target: dnsmessage.MustNewName("storage-2.local."), Port: 9000},
+	)
+	select {
+	case nodes := <-changes:
+		assert.Len(t, nodes, 2)
+	case <-time.After(2 * time.Second):
+		t.Fatal("change of the records was not reported")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := DefaultConfig()
+	assert.EqualError(t, config.Validate(), "dns discovery needs a record name")
+	config.Name = "_minio._tcp.storage.local"
+	assert.EqualError(t, config.Validate(), "dns discovery needs a secrets file")
+	config.SecretsFile = "secrets.yaml"
+	assert.NoError(t, config.Validate())
+	config.RefreshInterval = 0
+	assert.EqualError(t, config.Validate(), "dns refresh interval must be positive")
+}
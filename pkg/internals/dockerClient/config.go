@@ -0,0 +1,89 @@
+package dockerClient
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/placement"
+)
+
+// Labels carrying the role and placement metadata of a node container
+const (
+	LabelRole   = "objstore.role"
+	LabelZone   = "objstore.zone"
+	LabelWeight = "objstore.weight"
+)
+
+// Config selects the containers that are Minio nodes and how they are reached
+type Config struct {
+	// NamePattern is a regular expression the container name must match, empty matches every container
+	NamePattern string
+	// Labels must all be set on a container, a label with an empty value only has to be present
+	Labels map[string]string
+	// Network is the Docker network the nodes are reached on, empty uses each container's network mode
+	Network string
+	// APIPort is the port of the Minio S3 API inside the containers
+	APIPort string
+}
+
+// DefaultConfig returns the selection matching the containers of the bundled docker-compose file
+func DefaultConfig() Config {
+	return Config{
+		NamePattern: minioNodeName,
+		APIPort:     s3MinioApiPort,
+	}
+}
+
+// Validate checks the name pattern and the API port
+func (c Config) Validate() error {
+	if _, err := regexp.Compile(c.NamePattern); err != nil {
+		return fmt.Errorf("invalid node name pattern: %w", err)
+	}
+	if port, err := strconv.Atoi(c.APIPort); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid node API port %q", c.APIPort)
+	}
+	return nil
+}
+
+// ParseLabels parses a comma separated list of key=value labels, a key without value only has to be present
+func ParseLabels(labels string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	for _, label := range strings.Split(labels, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(label, "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid label %q", label)
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
+
+// matchesLabels reports whether a container carries all required labels
+func (c Config) matchesLabels(labels map[string]string) bool {
+	for key, value := range c.Labels {
+		actual, ok := labels[key]
+		if !ok || (value != "" && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeWeight reads the placement weight of a node from its labels, nodes without a weight have weight 1
+func nodeWeight(labels map[string]string) (int, error) {
+	value, ok := labels[LabelWeight]
+	if !ok {
+		return 1, nil
+	}
+	weight, err := strconv.Atoi(value)
+	if err != nil || weight < 1 || weight > placement.MaxWeight {
+		return 0, fmt.Errorf("invalid %s label %q, must be an integer between 1 and %d", LabelWeight, value, placement.MaxWeight)
+	}
+	return weight, nil
+}
@@ -0,0 +1,84 @@
+package dockerClient
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabels(t *testing.T) {
+	testCases := []struct {
+		name           string
+		labels         string
+		expectedLabels map[string]string
+		expectedError  string
+	}{
+		{name: "Empty", labels: "", expectedLabels: map[string]string{}},
+		{name: "Key And Value", labels: "objstore.role=node", expectedLabels: map[string]string{"objstore.role": "node"}},
+		{name: "Presence Only", labels: "objstore.role, objstore.zone=eu-1 ", expectedLabels: map[string]string{"objstore.role": "", "objstore.zone": "eu-1"}},
+		{name: "Missing Key", labels: "=node", expectedError: `invalid label "=node"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			labels, err := ParseLabels(tc.labels)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedLabels, labels)
+		})
+	}
+}
+
+func TestIsMinioNode(t *testing.T) {
+	config := DefaultConfig()
+	config.Labels = map[string]string{LabelRole: "node", LabelZone: ""}
+	client := &DockerClient{config: config, namePattern: regexp.MustCompile(config.NamePattern)}
+
+	testCases := []struct {
+		name     string
+		labels   map[string]string
+		expected bool
+	}{
+		{name: "/amazin-object-storage-node-1", labels: map[string]string{LabelRole: "node", LabelZone: "a"}, expected: true},
+		{name: "/amazin-object-storage-node-1", labels: map[string]string{LabelRole: "gateway", LabelZone: "a"}},
+		{name: "/amazin-object-storage-node-1", labels: map[string]string{LabelRole: "node"}},
+		{name: "/gateway-container", labels: map[string]string{LabelRole: "node", LabelZone: "a"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, client.isMinioNode(tc.name, tc.labels))
+		})
+	}
+}
+
+func TestNodeWeight(t *testing.T) {
+	weight, err := nodeWeight(map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, weight)
+
+	weight, err = nodeWeight(map[string]string{LabelWeight: "3"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, weight)
+
+	_, err = nodeWeight(map[string]string{LabelWeight: "0"})
+	assert.EqualError(t, err, `invalid objstore.weight label "0", must be an integer between 1 and 100`)
+	_, err = nodeWeight(map[string]string{LabelWeight: "65535"})
+	assert.EqualError(t, err, `invalid objstore.weight label "65535", must be an integer between 1 and 100`)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, DefaultConfig().Validate())
+
+	config := DefaultConfig()
+	config.NamePattern = "node-("
+	assert.ErrorContains(t, config.Validate(), "invalid node name pattern")
+
+	config = DefaultConfig()
+	config.APIPort = "http"
+	assert.EqualError(t, config.Validate(), `invalid node API port "http"`)
+}
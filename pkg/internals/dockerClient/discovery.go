@@ -0,0 +1,18 @@
+package dockerClient
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Discovery finds the Minio nodes of the cluster and follows changes of the node set
+type Discovery interface {
+	// DiscoverMinioNodes returns the nodes that are currently part of the cluster
+	DiscoverMinioNodes(ctx context.Context) ([]MinioNode, error)
+	// Watch calls onChange with the current nodes whenever the node set may have changed,
+	// until the context is cancelled
+	Watch(ctx context.Context, logger *zap.Logger, onChange func([]MinioNode))
+}
+
+var _ Discovery = (*DockerClient)(nil)
@@ -4,15 +4,23 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	"go.uber.org/zap"
 )
 
-const s3MinioApiPort = "9000"
+const (
+	s3MinioApiPort = "9000"
+	minioNodeName  = "amazin-object-storage-node"
+	// watchRetryDelay is the time to wait before subscribing again after the event stream failed
+	watchRetryDelay = 5 * time.Second
+)
 
 // MinioNode represents a discovered Minio node
 type MinioNode struct {
@@ -22,30 +30,41 @@ type MinioNode struct {
 	Port      string
 	AccessKey string
 	SecretKey string
+	// Zone is the failure domain of the node, taken from the objstore.zone label
+	Zone string
+	// Weight is the node's relative share of the objects, taken from the objstore.weight label
+	Weight int
+	// Labels are the labels of the node's container
+	Labels map[string]string
 }
 
 // DockerClient wraps the Docker API client
 type DockerClient struct {
-	client *client.Client
+	client      *client.Client
+	config      Config
+	namePattern *regexp.Regexp
+	logger      *zap.Logger
 }
 
-// NewClient creates a new Docker client
-func NewClient() (*DockerClient, error) {
+// NewClient creates a new Docker client discovering the containers selected by config
+func NewClient(config Config, logger *zap.Logger) (*DockerClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Docker client: %w", err)
 	}
-	return &DockerClient{client: cli}, nil
+	return &DockerClient{client: cli, config: config, namePattern: regexp.MustCompile(config.NamePattern), logger: logger}, nil
 }
 
-// DiscoverMinioNodes finds all running Minio nodes
+// DiscoverMinioNodes finds all running Minio nodes. Containers with invalid labels are logged and
+// left out, so one mislabeled container does not stop the other nodes from being updated.
 func (d *DockerClient) DiscoverMinioNodes(ctx context.Context) ([]MinioNode, error) {
-	// Create filter to find Minio containers
-	filter := filters.NewArgs()
-	filter.Add("name", "^/amazin-object-storage-node$")
-
+	// Labels are filtered by Docker, names are matched against the pattern below
 	containers, err := d.client.ContainerList(ctx, container.ListOptions{
-		// Filters: filter,
+		Filters: d.labelFilter(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list containers: %w", err)
@@ -53,7 +72,13 @@ func (d *DockerClient) DiscoverMinioNodes(ctx context.Context) ([]MinioNode, err
 
 	var nodes []MinioNode
 	for _, container := range containers {
-		if !strings.Contains(container.Names[0], "amazin-object-storage-node") {
+		if !d.isMinioNode(container.Names[0], container.Labels) {
+			continue
+		}
+
+		weight, err := nodeWeight(container.Labels)
+		if err != nil {
+			d.logger.Warn("Skipping container with invalid labels", zap.String("container", container.Names[0]), zap.Error(err))
 			continue
 		}
 
@@ -73,8 +98,12 @@ func (d *DockerClient) DiscoverMinioNodes(ctx context.Context) ([]MinioNode, err
 
 		// Get IP address from the object storage network
 		var ipAddress string
+		networkName := d.config.Network
+		if networkName == "" {
+			networkName = container.HostConfig.NetworkMode
+		}
 		if networks := inspect.NetworkSettings.Networks; networks != nil {
-			if network, ok := networks[container.HostConfig.NetworkMode]; ok {
+			if network, ok := networks[networkName]; ok {
 				ipAddress = network.IPAddress
 			}
 		}
@@ -94,14 +123,16 @@ func (d *DockerClient) DiscoverMinioNodes(ctx context.Context) ([]MinioNode, err
 		// 	continue
 		// }
 
-		// Default Minio API port is 9000
 		node := MinioNode{
 			ID:        container.ID,
 			Name:      strings.TrimPrefix(container.Names[0], "/"),
 			IPAddress: ipAddress,
-			Port:      s3MinioApiPort, // Minio s3 API port
+			Port:      d.config.APIPort, // Minio s3 API port
 			AccessKey: accessKey,
 			SecretKey: secretKey,
+			Zone:      container.Labels[LabelZone],
+			Weight:    weight,
+			Labels:    container.Labels,
 		}
 
 		nodes = append(nodes, node)
@@ -110,9 +141,95 @@ func (d *DockerClient) DiscoverMinioNodes(ctx context.Context) ([]MinioNode, err
 	return nodes, nil
 }
 
-// ValidateNodeConnection checks if a node is accessible
-func (d *DockerClient) ValidateNodeConnection(node MinioNode) bool {
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%s", node.IPAddress, node.Port), 2*time.Second)
+// Watch subscribes to the start, die and destroy events of Minio containers and calls onChange with
+// the rediscovered nodes after every event. The subscription is renewed when the event stream fails,
+// Watch returns once the context is cancelled.
+func (d *DockerClient) Watch(ctx context.Context, logger *zap.Logger, onChange func([]MinioNode)) {
+	filter := d.labelFilter()
+	for _, arg := range []filters.KeyValuePair{
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("event", string(events.ActionStart)),
+		filters.Arg("event", string(events.ActionDie)),
+		filters.Arg("event", string(events.ActionDestroy)),
+	} {
+		filter.Add(arg.Key, arg.Value)
+	}
+
+	for {
+		messages, errs := d.client.Events(ctx, events.ListOptions{Filters: filter})
+
+		// Events missed while the stream was down are caught up by discovering again
+		d.rediscover(ctx, logger, onChange)
+
+		err := d.consumeEvents(ctx, logger, messages, errs, onChange)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("Docker event stream failed, subscribing again", zap.Duration("retry_in", watchRetryDelay), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+// consumeEvents handles the events of one subscription until the stream fails
+func (d *DockerClient) consumeEvents(ctx context.Context, logger *zap.Logger, messages <-chan events.Message, errs <-chan error, onChange func([]MinioNode)) error {
+	for {
+		select {
+		case err := <-errs:
+			return err
+		case message := <-messages:
+			// The attributes of container events hold the container's name and labels
+			name := message.Actor.Attributes["name"]
+			if !d.isMinioNode(name, message.Actor.Attributes) {
+				continue
+			}
+			logger.Info("Minio container event",
+				zap.String("action", string(message.Action)),
+				zap.String("container_id", message.Actor.ID),
+				zap.String("container_name", name))
+			d.rediscover(ctx, logger, onChange)
+		}
+	}
+}
+
+// rediscover lists the Minio nodes and passes them to onChange
+func (d *DockerClient) rediscover(ctx context.Context, logger *zap.Logger, onChange func([]MinioNode)) {
+	nodes, err := d.DiscoverMinioNodes(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Failed to discover Minio nodes", zap.Error(err))
+		}
+		return
+	}
+	onChange(nodes)
+}
+
+// labelFilter selects the containers carrying the configured labels
+func (d *DockerClient) labelFilter() filters.Args {
+	filter := filters.NewArgs()
+	for key, value := range d.config.Labels {
+		if value == "" {
+			filter.Add("label", key)
+		} else {
+			filter.Add("label", key+"="+value)
+		}
+	}
+	return filter
+}
+
+// isMinioNode reports whether a container is a Minio node
+func (d *DockerClient) isMinioNode(name string, labels map[string]string) bool {
+	return d.namePattern.MatchString(strings.TrimPrefix(name, "/")) && d.config.matchesLabels(labels)
+}
+
+// ValidateNodeConnection checks if a node is accessible. The check gives up when ctx is done.
+func ValidateNodeConnection(ctx context.Context, node MinioNode) bool {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(node.IPAddress, node.Port))
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,157 @@
+package fileDiscovery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/placement"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultPort = "9000"
+	// DefaultPollInterval is how often the file is checked for changes
+	DefaultPollInterval = 5 * time.Second
+)
+
+// nodesFile is the layout of the file, JSON files are read as YAML
+type nodesFile struct {
+	Nodes []nodeEntry `yaml:"nodes"`
+}
+
+type nodeEntry struct {
+	// ID identifies the node for placement and defaults to the name. Changing it moves objects.
+	ID        string            `yaml:"id"`
+	Name      string            `yaml:"name"`
+	Address   string            `yaml:"address"`
+	Port      string            `yaml:"port"`
+	AccessKey string            `yaml:"access_key"`
+	SecretKey string            `yaml:"secret_key"`
+	Zone      string            `yaml:"zone"`
+	Weight    int               `yaml:"weight"`
+	Labels    map[string]string `yaml:"labels"`
+}
+
+// FileDiscovery reads the nodes from a YAML or JSON file and reloads it when it changes
+type FileDiscovery struct {
+	path         string
+	pollInterval time.Duration
+}
+
+var _ docker.Discovery = (*FileDiscovery)(nil)
+
+// New creates a discovery for the nodes listed in the file at path
+func New(path string, pollInterval time.Duration) *FileDiscovery {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	return &FileDiscovery{path: path, pollInterval: pollInterval}
+}
+
+// DiscoverMinioNodes reads the nodes from the file
+func (f *FileDiscovery) DiscoverMinioNodes(ctx context.Context) ([]docker.MinioNode, error) {
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nodes file: %w", err)
+	}
+	return parseNodes(content)
+}
+
+// Watch checks the file for changes every poll interval and calls onChange with the nodes of the
+// first and every new version. Versions that cannot be read are logged and skipped, keeping the previous nodes.
+func (f *FileDiscovery) Watch(ctx context.Context, logger *zap.Logger, onChange func([]docker.MinioNode)) {
+	// Polling also notices files that are replaced by a rename, like mounted config maps.
+	// The first poll always reports the nodes, in case they could not be read at startup.
+	var last []byte
+
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		content, err := os.ReadFile(f.path)
+		if err != nil {
+			logger.Error("Failed to read nodes file", zap.String("path", f.path), zap.Error(err))
+			continue
+		}
+		if bytes.Equal(content, last) {
+			continue
+		}
+		last = content
+
+		nodes, err := parseNodes(content)
+		if err != nil {
+			logger.Error("Ignoring invalid nodes file", zap.String("path", f.path), zap.Error(err))
+			continue
+		}
+		logger.Info("Nodes file changed", zap.String("path", f.path), zap.Int("minio nodes", len(nodes)))
+		onChange(nodes)
+	}
+}
+
+// parseNodes parses and validates the content of a nodes file
+func parseNodes(content []byte) ([]docker.MinioNode, error) {
+	// An empty file is most likely being written, an empty cluster is listed as "nodes: []"
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, fmt.Errorf("nodes file is empty")
+	}
+
+	var file nodesFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse nodes file: %w", err)
+	}
+
+	nodes := make([]docker.MinioNode, 0, len(file.Nodes))
+	seen := make(map[string]bool, len(file.Nodes))
+	for i, entry := range file.Nodes {
+		node := docker.MinioNode{
+			ID:        entry.ID,
+			Name:      entry.Name,
+			IPAddress: entry.Address,
+			Port:      entry.Port,
+			AccessKey: entry.AccessKey,
+			SecretKey: entry.SecretKey,
+			Zone:      entry.Zone,
+			Weight:    entry.Weight,
+			Labels:    entry.Labels,
+		}
+		if node.ID == "" {
+			node.ID = node.Name
+		}
+		if node.Name == "" {
+			node.Name = node.ID
+		}
+		if node.Port == "" {
+			node.Port = defaultPort
+		}
+		if node.Weight == 0 {
+			node.Weight = 1
+		}
+
+		switch {
+		case node.ID == "":
+			return nil, fmt.Errorf("node %d: id or name is required", i+1)
+		case node.IPAddress == "":
+			return nil, fmt.Errorf("node %s: address is required", node.ID)
+		case node.Weight < 0 || node.Weight > placement.MaxWeight:
+			return nil, fmt.Errorf("node %s: weight must be between 1 and %d", node.ID, placement.MaxWeight)
+		case seen[node.ID]:
+			return nil, fmt.Errorf("node %s is listed more than once", node.ID)
+		}
+		seen[node.ID] = true
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
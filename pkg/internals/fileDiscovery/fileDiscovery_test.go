@@ -0,0 +1,105 @@
+package fileDiscovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestParseNodes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		expectedNodes []docker.MinioNode
+		expectedError string
+	}{
+		{
+			name: "YAML",
+			content: `
+nodes:
+  - name: node-1
+    address: 10.0.0.2
+    access_key: ring
+    secret_key: treepotato
+    zone: eu-1
+    weight: 2
+  - id: b7
+    name: node-2
+    address: storage-2.local
+    port: "9100"
+`,
+			expectedNodes: []docker.MinioNode{
+				{ID: "node-1", Name: "node-1", IPAddress: "10.0.0.2", Port: "9000", AccessKey: "ring", SecretKey: "treepotato", Zone: "eu-1", Weight: 2},
+				{ID: "b7", Name: "node-2", IPAddress: "storage-2.local", Port: "9100", Weight: 1},
+			},
+		},
+		{
+			name:    "JSON",
+			content: `{"nodes": [{"id": "node-1", "address": "10.0.0.2", "labels": {"objstore.role": "node"}}]}`,
+			expectedNodes: []docker.MinioNode{
+				{ID: "node-1", Name: "node-1", IPAddress: "10.0.0.2", Port: "9000", Weight: 1, Labels: map[string]string{"objstore.role": "node"}},
+			},
+		},
+		{name: "No Nodes", content: "nodes: []", expectedNodes: []docker.MinioNode{}},
+		{name: "Empty File", content: " \n", expectedError: "nodes file is empty"},
+		{name: "Missing ID", content: "nodes: [{address: 10.0.0.2}]", expectedError: "node 1: id or name is required"},
+		{name: "Missing Address", content: "nodes: [{id: node-1}]", expectedError: "node node-1: address is required"},
+		{name: "Duplicate ID", content: "nodes: [{id: node-1, address: a}, {id: node-1, address: b}]", expectedError: "node node-1 is listed more than once"},
+		{name: "Negative Weight", content: "nodes: [{id: node-1, address: a, weight: -1}]", expectedError: "node node-1: weight must be between 1 and 100"},
+		{name: "Weight Too Large", content: "nodes: [{id: node-1, address: a, weight: 1000}]", expectedError: "node node-1: weight must be between 1 and 100"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, err := parseNodes([]byte(tc.content))
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedNodes, nodes)
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("nodes: [{id: node-1, address: a}]"), 0o644))
+
+	discovery := New(path, 10*time.Millisecond)
+	nodes, err := discovery.DiscoverMinioNodes(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan []docker.MinioNode, 10)
+	go discovery.Watch(ctx, zap.NewNop(), func(nodes []docker.MinioNode) { changes <- nodes })
+
+	// The first version is reported
+	select {
+	case nodes := <-changes:
+		assert.Len(t, nodes, 1)
+	case <-time.After(time.Second):
+		t.Fatal("first version of the nodes file was not reported")
+	}
+
+	// Invalid versions are skipped, the next valid version is reported
+	assert.NoError(t, os.WriteFile(path, []byte("nodes: [{id: node-1}]"), 0o644))
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(path, []byte("nodes: [{id: node-1, address: a}, {id: node-2, address: b}]"), 0o644))
+
+	select {
+	case nodes := <-changes:
+		assert.Len(t, nodes, 2)
+	case <-time.After(time.Second):
+		t.Fatal("change of the nodes file was not reported")
+	}
+	assert.Empty(t, changes)
+}
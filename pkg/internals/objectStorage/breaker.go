@@ -0,0 +1,157 @@
+package objectStorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+// States of a circuit breaker
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// ErrNodeUnavailable is returned without contacting a node while its circuit breaker is open
+var ErrNodeUnavailable = errors.New("node unavailable")
+
+// NodeUnavailableError reports a node whose circuit breaker is open and when it will be tried again
+type NodeUnavailableError struct {
+	Node       string
+	RetryAfter time.Duration
+}
+
+func (e *NodeUnavailableError) Error() string {
+	return fmt.Sprintf("node %s unavailable, retry after %s", e.Node, e.RetryAfter)
+}
+
+func (e *NodeUnavailableError) Is(target error) bool {
+	return target == ErrNodeUnavailable || target == ErrUnavailable
+}
+
+// RetryAfterSeconds returns the time until the node is tried again in whole seconds, at least 1
+func (e *NodeUnavailableError) RetryAfterSeconds() int {
+	return max(int(math.Ceil(e.RetryAfter.Seconds())), 1)
+}
+
+// circuitBreaker stops sending requests to a node after BreakerFailureThreshold consecutive
+// failures. Once BreakerOpenTimeout has passed, up to BreakerHalfOpenRequests requests are let
+// through: the breaker closes when one succeeds and opens again when one fails.
+// A nil breaker lets every request through.
+type circuitBreaker struct {
+	node   string
+	config Config
+	logger *zap.Logger
+
+	mutex    sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+func newCircuitBreaker(node string, config Config, logger *zap.Logger) *circuitBreaker {
+	return &circuitBreaker{node: node, config: config, logger: logger, state: BreakerClosed}
+}
+
+// allow returns a NodeUnavailableError when the request must not be sent to the node.
+// Every allowed request must be followed by a call to record.
+func (b *circuitBreaker) allow() error {
+	if b == nil || b.config.BreakerFailureThreshold == 0 {
+		return nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == BreakerOpen {
+		if wait := b.config.BreakerOpenTimeout - time.Since(b.openedAt); wait > 0 {
+			return &NodeUnavailableError{Node: b.node, RetryAfter: wait}
+		}
+		b.transition(BreakerHalfOpen)
+	}
+	if b.state == BreakerHalfOpen {
+		if b.probes >= b.config.BreakerHalfOpenRequests {
+			return &NodeUnavailableError{Node: b.node, RetryAfter: b.config.BreakerOpenTimeout}
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of an allowed request
+func (b *circuitBreaker) record(err error) {
+	if b == nil || b.config.BreakerFailureThreshold == 0 {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+
+	switch {
+	case !isNodeFailure(err):
+		b.failures = 0
+		if b.state == BreakerHalfOpen {
+			b.transition(BreakerClosed)
+		}
+	case errors.Is(err, context.Canceled):
+		// The caller gave up, which says nothing about the node
+	default:
+		b.failures++
+		if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.config.BreakerFailureThreshold) {
+			b.openedAt = time.Now()
+			b.transition(BreakerOpen)
+		}
+	}
+}
+
+// transition changes the state of the breaker. The caller must hold the mutex.
+func (b *circuitBreaker) transition(state string) {
+	b.logger.Warn("Circuit breaker state changed",
+		zap.String("node_name", b.node), zap.String("from", b.state), zap.String("to", state), zap.Int("failures", b.failures))
+	b.state = state
+	b.probes = 0
+	if state == BreakerClosed {
+		b.failures = 0
+	}
+}
+
+// isNodeFailure reports whether an error means the node is not working. Errors the node answered
+// with, such as a missing object or a failed precondition, show it is working.
+func isNodeFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	response := minio.ToErrorResponse(err)
+	return response.StatusCode == 0 || response.StatusCode >= 500
+}
+
+// call runs an operation against a replica through the circuit breaker of its node
+func (r replica) call(operation func() error) error {
+	if err := r.breaker.allow(); err != nil {
+		return err
+	}
+	err := operation()
+	r.breaker.record(err)
+	return err
+}
+
+// preferUnavailable picks the error reported for a failed quorum. Open circuit breakers are
+// preferred so callers learn when to retry.
+func preferUnavailable(current error, err error) error {
+	if current == nil || (errors.Is(err, ErrNodeUnavailable) && !errors.Is(current, ErrNodeUnavailable)) {
+		return err
+	}
+	return current
+}
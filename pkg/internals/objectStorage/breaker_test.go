@@ -0,0 +1,84 @@
+package objectStorage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	config := DefaultConfig()
+	config.BreakerFailureThreshold = 2
+	config.BreakerOpenTimeout = 20 * time.Millisecond
+	config.BreakerHalfOpenRequests = 1
+	breaker := newCircuitBreaker("node-1", config, zap.NewNop())
+	nodeDown := errors.New("dial tcp: connection refused")
+
+	// Answers of a working node and abandoned requests do not count as failures
+	for _, err := range []error{
+		nodeDown,
+		minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound},
+		nodeDown,
+		context.Canceled,
+	} {
+		assert.NoError(t, breaker.allow())
+		breaker.record(err)
+	}
+	assert.Equal(t, BreakerClosed, breaker.state)
+
+	// Consecutive failures open the breaker
+	assert.NoError(t, breaker.allow())
+	breaker.record(minio.ErrorResponse{Code: "InternalError", StatusCode: http.StatusInternalServerError})
+	assert.Equal(t, BreakerOpen, breaker.state)
+
+	err := breaker.allow()
+	assert.ErrorIs(t, err, ErrNodeUnavailable)
+	var unavailable *NodeUnavailableError
+	assert.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, "node-1", unavailable.Node)
+	assert.Equal(t, 1, unavailable.RetryAfterSeconds())
+
+	// After the timeout a single request tries the node again
+	time.Sleep(config.BreakerOpenTimeout)
+	assert.NoError(t, breaker.allow())
+	assert.Equal(t, BreakerHalfOpen, breaker.state)
+	assert.ErrorIs(t, breaker.allow(), ErrNodeUnavailable)
+	breaker.record(nodeDown)
+	assert.Equal(t, BreakerOpen, breaker.state)
+
+	time.Sleep(config.BreakerOpenTimeout)
+	assert.NoError(t, breaker.allow())
+	breaker.record(nil)
+	assert.Equal(t, BreakerClosed, breaker.state)
+	assert.NoError(t, breaker.allow())
+	breaker.record(nil)
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	config := DefaultConfig()
+	config.BreakerFailureThreshold = 0
+	breaker := newCircuitBreaker("node-1", config, zap.NewNop())
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, breaker.allow())
+		breaker.record(errors.New("timeout"))
+	}
+
+	// Replicas without a breaker are never blocked
+	var missing *circuitBreaker
+	assert.NoError(t, missing.allow())
+}
+
+func TestPreferUnavailable(t *testing.T) {
+	failed := errors.New("timeout")
+	unavailable := &NodeUnavailableError{Node: "node-1", RetryAfter: time.Second}
+
+	assert.Equal(t, failed, preferUnavailable(nil, failed))
+	assert.Equal(t, unavailable, preferUnavailable(failed, unavailable))
+	assert.Equal(t, unavailable, preferUnavailable(unavailable, failed))
+}
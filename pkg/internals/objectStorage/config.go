@@ -0,0 +1,155 @@
+package objectStorage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/dnsDiscovery"
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/placement"
+)
+
+// Node discovery mechanisms
+const (
+	DiscoveryDocker = "docker"
+	DiscoveryFile   = "file"
+	DiscoveryDNS    = "dns"
+)
+
+// Config holds the settings of the storage backends
+type Config struct {
+	// ReplicationFactor is the number of distinct nodes every object is stored on
+	ReplicationFactor int
+	// WriteQuorum is the number of replicas that must acknowledge a write
+	WriteQuorum int
+	// ReadQuorum is the number of replicas that must agree on an object before it is returned
+	ReadQuorum int
+	// Placement is the strategy mapping objects to nodes, see the placement package
+	Placement string
+	// VirtualNodes is the number of points every node owns on the consistent hash ring
+	VirtualNodes int
+	// RebalanceObjectsPerSecond limits how many objects the rebalancer migrates per second, 0 is unlimited
+	RebalanceObjectsPerSecond float64
+	// RebalanceBytesPerSecond limits the bandwidth used by the rebalancer, 0 is unlimited
+	RebalanceBytesPerSecond int64
+	// RebalanceOnChange starts a rebalance whenever nodes join or leave
+	RebalanceOnChange bool
+	// ReadRepair copies objects found outside their replicas to the replicas when they are read
+	ReadRepair bool
+	// HealthCheckInterval is the time between probes of every node, 0 disables health checks
+	HealthCheckInterval time.Duration
+	// HealthCheckTimeout bounds a single probe
+	HealthCheckTimeout time.Duration
+	// UnhealthyThreshold is the number of consecutive failed probes after which a node is ejected
+	UnhealthyThreshold int
+	// HealthyThreshold is the number of consecutive successful probes after which a node is re-admitted
+	HealthyThreshold int
+	// BreakerFailureThreshold is the number of consecutive failures after which requests to a node
+	// fail fast, 0 disables the circuit breakers
+	BreakerFailureThreshold int
+	// BreakerOpenTimeout is how long requests to a node fail fast before it is tried again
+	BreakerOpenTimeout time.Duration
+	// BreakerHalfOpenRequests is the number of requests let through to try a node again
+	BreakerHalfOpenRequests int
+	// Discovery is the mechanism finding the Minio nodes, docker, file or dns
+	Discovery string
+	// Docker selects the containers discovered as Minio nodes
+	Docker docker.Config
+	// NodesFile is the YAML or JSON file listing the nodes for file discovery
+	NodesFile string
+	// DNS describes the records listing the nodes for dns discovery
+	DNS dnsDiscovery.Config
+	// FilesystemRoot is the directory the fs storage type keeps its objects in
+	FilesystemRoot string
+	// S3 describes the bucket of the s3 storage type
+	S3 S3Config
+	// MemoryMaxBytes limits the object content the memory storage type holds before it evicts
+	// the least recently used objects, 0 is unlimited
+	MemoryMaxBytes int64
+	// Tiered describes the tiers of the tiered storage type
+	Tiered TieredConfig
+	// EncryptionKeyFile is the YAML file holding the master keys, objects are stored in plaintext without it
+	EncryptionKeyFile string
+	// EncryptionRotate wraps the data keys of objects under older master keys with the current one,
+	// and encrypts objects stored before encryption was enabled, once at startup
+	EncryptionRotate bool
+}
+
+// DefaultConfig returns the configuration used when no flags are given,
+// which stores every object on a single node
+func DefaultConfig() Config {
+	return Config{
+		ReplicationFactor:       1,
+		WriteQuorum:             1,
+		ReadQuorum:              1,
+		Placement:               placement.StrategyRing,
+		VirtualNodes:            placement.DefaultVirtualNodes,
+		ReadRepair:              true,
+		HealthCheckInterval:     10 * time.Second,
+		HealthCheckTimeout:      2 * time.Second,
+		UnhealthyThreshold:      3,
+		HealthyThreshold:        2,
+		BreakerFailureThreshold: 5,
+		BreakerOpenTimeout:      30 * time.Second,
+		BreakerHalfOpenRequests: 1,
+		Discovery:               DiscoveryDocker,
+		Docker:                  docker.DefaultConfig(),
+		DNS:                     dnsDiscovery.DefaultConfig(),
+		FilesystemRoot:          "data",
+		S3:                      DefaultS3Config(),
+		Tiered:                  DefaultTieredConfig(),
+	}
+}
+
+// Validate checks that the settings are consistent with each other
+func (c Config) Validate() error {
+	if c.ReplicationFactor < 1 {
+		return fmt.Errorf("replication factor must be at least 1")
+	}
+	if c.WriteQuorum < 1 || c.WriteQuorum > c.ReplicationFactor {
+		return fmt.Errorf("write quorum must be between 1 and the replication factor %d", c.ReplicationFactor)
+	}
+	if c.ReadQuorum < 1 || c.ReadQuorum > c.ReplicationFactor {
+		return fmt.Errorf("read quorum must be between 1 and the replication factor %d", c.ReplicationFactor)
+	}
+	if _, err := placement.New(c.Placement, nil, c.VirtualNodes); err != nil {
+		return err
+	}
+	if c.VirtualNodes < 1 {
+		return fmt.Errorf("virtual nodes must be at least 1")
+	}
+	if c.RebalanceObjectsPerSecond < 0 || c.RebalanceBytesPerSecond < 0 {
+		return fmt.Errorf("rebalance limits must not be negative")
+	}
+	if c.HealthCheckInterval < 0 || c.HealthCheckTimeout < 0 {
+		return fmt.Errorf("health check interval and timeout must not be negative")
+	}
+	if c.HealthCheckInterval > 0 && c.HealthCheckTimeout == 0 {
+		return fmt.Errorf("health check timeout must be positive when health checks are enabled")
+	}
+	if c.UnhealthyThreshold < 1 || c.HealthyThreshold < 1 {
+		return fmt.Errorf("health check thresholds must be at least 1")
+	}
+	if c.BreakerFailureThreshold < 0 || c.BreakerOpenTimeout < 0 || c.BreakerHalfOpenRequests < 1 {
+		return fmt.Errorf("circuit breaker settings must not be negative and let at least 1 request through")
+	}
+	if c.MemoryMaxBytes < 0 {
+		return fmt.Errorf("memory limit must not be negative")
+	}
+	if c.EncryptionRotate && c.EncryptionKeyFile == "" {
+		return fmt.Errorf("key rotation needs a key file")
+	}
+	switch c.Discovery {
+	case DiscoveryDocker:
+		return c.Docker.Validate()
+	case DiscoveryFile:
+		if c.NodesFile == "" {
+			return fmt.Errorf("file discovery needs a nodes file")
+		}
+	case DiscoveryDNS:
+		return c.DNS.Validate()
+	default:
+		return fmt.Errorf("unknown discovery %q", c.Discovery)
+	}
+	return nil
+}
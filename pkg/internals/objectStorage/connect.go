@@ -0,0 +1,73 @@
+package objectStorage
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"go.uber.org/zap"
+)
+
+const (
+	connectInitialBackoff = 500 * time.Millisecond
+	connectMaxBackoff     = 30 * time.Second
+)
+
+// connectAttempt is the background initialisation of a node's client
+type connectAttempt struct {
+	cancel context.CancelFunc
+}
+
+// connect initialises the client of a node in the background, retrying with exponential backoff
+// until it succeeds. An attempt still running for the node is cancelled first.
+func (s *minioStorageService) connect(node docker.MinioNode) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempt := &connectAttempt{cancel: cancel}
+
+	s.clientsMutex.Lock()
+	if previous, ok := s.connecting[node.ID]; ok {
+		previous.cancel()
+	}
+	s.connecting[node.ID] = attempt
+	s.clientsMutex.Unlock()
+
+	go func() {
+		defer func() {
+			cancel()
+			s.clientsMutex.Lock()
+			// A newer attempt may have replaced this one
+			if s.connecting[node.ID] == attempt {
+				delete(s.connecting, node.ID)
+			}
+			s.clientsMutex.Unlock()
+		}()
+
+		for retry := 0; ; retry++ {
+			err := s.initializeClient(ctx, node)
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+
+			delay := connectBackoff(retry)
+			s.logger.Warn("Failed to initialize client for node, retrying",
+				zap.String("node_name", node.Name), zap.Int("attempt", retry+1), zap.Duration("retry_in", delay), zap.Error(err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+	}()
+}
+
+// connectBackoff returns the delay before the next attempt. The delay doubles with every attempt
+// up to connectMaxBackoff, and half of it is random so nodes are not retried in lockstep.
+func connectBackoff(attempt int) time.Duration {
+	backoff := connectMaxBackoff
+	if attempt < 16 {
+		backoff = min(connectInitialBackoff<<attempt, connectMaxBackoff)
+	}
+	return backoff/2 + rand.N(backoff/2+1)
+}
@@ -0,0 +1,41 @@
+package objectStorage
+
+import (
+	"testing"
+	"time"
+
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectBackoff(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		backoff := min(connectInitialBackoff<<min(attempt, 16), connectMaxBackoff)
+		delay := connectBackoff(attempt)
+		assert.GreaterOrEqual(t, delay, backoff/2)
+		assert.LessOrEqual(t, delay, backoff)
+	}
+	assert.LessOrEqual(t, connectBackoff(0), connectInitialBackoff)
+	assert.GreaterOrEqual(t, connectBackoff(10), connectMaxBackoff/2)
+}
+
+func TestConnectCancelledWhenNodeLeaves(t *testing.T) {
+	nodes := newTestNodes(2)
+	service := newTestService(nodes, DefaultConfig())
+
+	// Nothing listens on the port, so the client is retried in the background
+	unreachable := docker.MinioNode{ID: "id-3", Name: "node-3", IPAddress: "127.0.0.1", Port: "1"}
+	service.UpdateNodes(append(nodes, unreachable))
+	assert.Contains(t, service.connecting, unreachable.ID)
+	assert.Len(t, service.currentNodes(), 3)
+
+	service.UpdateNodes(nodes)
+	assert.NotContains(t, service.connecting, unreachable.ID)
+
+	// The abandoned attempt leaves nothing behind
+	time.Sleep(100 * time.Millisecond)
+	service.clientsMutex.RLock()
+	defer service.clientsMutex.RUnlock()
+	assert.NotContains(t, service.clients, unreachable.ID)
+	assert.NotContains(t, service.clientErrors, unreachable.ID)
+}
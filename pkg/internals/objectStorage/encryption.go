@@ -0,0 +1,627 @@
+package objectStorage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	utils "github.com/singhmeghna79/homework-object-storage/pkg"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// encryptionChunkSize is the content sealed at once, only a chunk is held in memory
+	encryptionChunkSize = 64 * 1024
+	// encryptionTagSize is the size of the authentication tag added to every chunk
+	encryptionTagSize = 16
+	// encryptionKeySize is the size of master and data keys, which selects AES-256
+	encryptionKeySize = 32
+)
+
+// User metadata storing how an object is encrypted. The entries are hidden from clients.
+const (
+	encryptionKeyIDMeta = "Encryption-Key-Id"
+	encryptionKeyMeta   = "Encryption-Key"
+	encryptionSizeMeta  = "Encryption-Size"
+)
+
+// keyFile is the YAML file holding the master keys, encoded in base64 and named by their ID
+type keyFile struct {
+	Current string            `yaml:"current"`
+	Keys    map[string]string `yaml:"keys"`
+}
+
+// masterKeys wraps the data keys of objects. New data keys are wrapped with the current key, the
+// other keys are kept to unwrap the keys of objects written before a rotation.
+type masterKeys struct {
+	path string
+
+	mutex   sync.RWMutex
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// LoadMasterKeys reads the master keys from a keyfile
+func LoadMasterKeys(path string) (*masterKeys, error) {
+	keys := &masterKeys{path: path}
+	if err := keys.load(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// load reads the keyfile again, so keys added by a rotation are picked up
+func (k *masterKeys) load() error {
+	content, err := os.ReadFile(k.path)
+	if err != nil {
+		return fmt.Errorf("failed to read key file: %w", err)
+	}
+	var file keyFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("failed to parse key file: %w", err)
+	}
+	if _, ok := file.Keys[file.Current]; !ok {
+		return fmt.Errorf("key file does not hold the current key %q", file.Current)
+	}
+
+	keys := make(map[string]cipher.AEAD, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != encryptionKeySize {
+			return fmt.Errorf("master key %q must be %d bytes encoded in base64", id, encryptionKeySize)
+		}
+		if keys[id], err = newGCM(key); err != nil {
+			return err
+		}
+	}
+
+	k.mutex.Lock()
+	k.current, k.keys = file.Current, keys
+	k.mutex.Unlock()
+	return nil
+}
+
+// currentID returns the ID of the key wrapping new data keys
+func (k *masterKeys) currentID() string {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	return k.current
+}
+
+// wrap encrypts the data key of an object with the current key. The object ID is authenticated,
+// so a wrapped key cannot be moved to another object.
+func (k *masterKeys) wrap(objectID string, dataKey []byte) (string, []byte, error) {
+	k.mutex.RLock()
+	id, aead := k.current, k.keys[k.current]
+	k.mutex.RUnlock()
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return id, aead.Seal(nonce, nonce, dataKey, []byte(objectID)), nil
+}
+
+// unwrap decrypts the data key of an object. An unknown key may have been added by a rotation
+// after the keyfile was read, so the keyfile is read again once.
+func (k *masterKeys) unwrap(objectID string, keyID string, wrapped []byte) ([]byte, error) {
+	k.mutex.RLock()
+	aead, ok := k.keys[keyID]
+	k.mutex.RUnlock()
+	if !ok {
+		if err := k.load(); err != nil {
+			return nil, err
+		}
+		k.mutex.RLock()
+		aead, ok = k.keys[keyID]
+		k.mutex.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("object is encrypted with unknown master key %q", keyID)
+		}
+	}
+
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("wrapped data key is too short")
+	}
+	dataKey, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(objectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// encryptedStorageService encrypts objects before they are passed on to the storage it wraps.
+//
+// Every object has its own data key, wrapped by a master key and stored in its metadata. The
+// content is split into chunks sealed with AES-256-GCM, so objects are streamed in both directions
+// and ranges are read by decrypting only the chunks covering them. The nonce of a chunk holds its
+// index and marks the final chunk, so reordered or truncated chunks fail to decrypt.
+//
+// ETags are those of the stored ciphertext, they change with every write like the content does.
+type encryptedStorageService struct {
+	inner  ObjectStorage
+	keys   *masterKeys
+	logger *zap.Logger
+}
+
+// NewEncryptedStorageService creates the storage encrypting the objects stored in inner
+func NewEncryptedStorageService(inner ObjectStorage, keys *masterKeys, logger *zap.Logger) *encryptedStorageService {
+	logger.Info("Encrypting objects", zap.String("key_file", keys.path), zap.String("current_key", keys.currentID()))
+	return &encryptedStorageService{inner: inner, keys: keys, logger: logger}
+}
+
+// Unwrap returns the storage holding the encrypted objects
+func (s *encryptedStorageService) Unwrap() []ObjectStorage {
+	return []ObjectStorage{s.inner}
+}
+
+// PutObject encrypts the object with a new data key while it is uploaded
+func (s *encryptedStorageService) PutObject(ctx *gin.Context, objectID string, data io.Reader, size int64, opts PutOptions) error {
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
+	}
+
+	encrypted, metadata, err := s.encrypt(objectID, data, size, opts.UserMetadata)
+	if err != nil {
+		return err
+	}
+	opts.UserMetadata = metadata
+	return s.inner.PutObject(ctx, objectID, encrypted, encryptedSize(size), opts)
+}
+
+// encrypt returns the reader encrypting size bytes of data with a new data key and the metadata
+// storing the wrapped key next to the user metadata
+func (s *encryptedStorageService) encrypt(objectID string, data io.Reader, size int64, userMetadata map[string]string) (io.Reader, map[string]string, error) {
+	dataKey := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyID, wrapped, err := s.keys.wrap(objectID, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newEncryptingReader(aead, data, size), encryptionMetadata(userMetadata, keyID, wrapped, size), nil
+}
+
+// GetObject decrypts the object, or only the chunks covering the requested range. Ranged reads
+// state the object first, as the range of the stored object depends on whether it is encrypted.
+func (s *encryptedStorageService) GetObject(ctx *gin.Context, objectID string, opts GetOptions) (io.ReadCloser, ObjectInfo, error) {
+	innerOpts, encrypted := opts, true
+	if opts.Range != nil {
+		stored, err := s.inner.StatObject(ctx, objectID)
+		if err != nil {
+			return nil, ObjectInfo{}, err
+		}
+		encryption, ok, err := encryptionOf(stored)
+		if err != nil {
+			return nil, ObjectInfo{}, err
+		}
+		if encrypted = ok; encrypted {
+			if err := checkDecryptedRange(*opts.Range, encryption.size); err != nil {
+				return nil, ObjectInfo{}, err
+			}
+			// The stored object may be shorter, the storages end ranges at the end of the object
+			innerOpts.Range = &ByteRange{
+				Start: opts.Range.Start / encryptionChunkSize * (encryptionChunkSize + encryptionTagSize),
+				End:   (opts.Range.End/encryptionChunkSize+1)*(encryptionChunkSize+encryptionTagSize) - 1,
+			}
+		}
+	}
+
+	obj, info, err := s.inner.GetObject(ctx, objectID, innerOpts)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	encryption, ok, err := encryptionOf(info)
+	if err != nil {
+		obj.Close()
+		return nil, ObjectInfo{}, err
+	}
+	if ok != encrypted {
+		// The object was rewritten since it was stated, for example by a key rotation
+		obj.Close()
+		return nil, ObjectInfo{}, NewError(ErrConflict, "object was rewritten while it was read", nil)
+	}
+	if !ok {
+		// Objects stored before encryption was enabled are served as they are
+		return obj, info, nil
+	}
+
+	aead, err := s.dataKey(objectID, encryption)
+	if err != nil {
+		obj.Close()
+		return nil, ObjectInfo{}, err
+	}
+
+	start, end := int64(0), encryption.size-1
+	if opts.Range != nil {
+		if err := checkDecryptedRange(*opts.Range, encryption.size); err != nil {
+			obj.Close()
+			return nil, ObjectInfo{}, err
+		}
+		start, end = opts.Range.Start, min(opts.Range.End, encryption.size-1)
+	}
+
+	reader := newDecryptingReader(aead, obj, start/encryptionChunkSize, max(end, 0)/encryptionChunkSize, encryption.size)
+	if encryption.size == 0 {
+		// Clients read no content from an empty object, its only chunk is opened here so a
+		// removed or modified chunk is detected
+		if _, err := io.Copy(io.Discard, reader); err != nil {
+			obj.Close()
+			return nil, ObjectInfo{}, err
+		}
+	}
+	if _, err := io.CopyN(io.Discard, reader, start%encryptionChunkSize); err != nil {
+		obj.Close()
+		return nil, ObjectInfo{}, err
+	}
+
+	info = encryption.objectInfo(info)
+	info.Size = end - start + 1
+	return decryptedObject{Reader: io.LimitReader(reader, info.Size), Closer: obj}, info, nil
+}
+
+// checkDecryptedRange checks that a range starts within size bytes of content
+func checkDecryptedRange(r ByteRange, size int64) error {
+	if r.Start < 0 || r.Start > r.End || r.Start >= size {
+		return NewError(ErrInvalidRange, fmt.Sprintf("invalid range: %d-%d of %d bytes", r.Start, r.End, size), nil)
+	}
+	return nil
+}
+
+// StatObject returns the metadata of the object with the size of its content
+func (s *encryptedStorageService) StatObject(ctx *gin.Context, objectID string) (ObjectInfo, error) {
+	info, err := s.inner.StatObject(ctx, objectID)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	encryption, ok, err := encryptionOf(info)
+	if err != nil || !ok {
+		return info, err
+	}
+	return encryption.objectInfo(info), nil
+}
+
+// DeleteObject removes the object, its data key is stored with it
+func (s *encryptedStorageService) DeleteObject(ctx *gin.Context, objectID string) error {
+	return s.inner.DeleteObject(ctx, objectID)
+}
+
+// ListObjects lists the objects with the size of their content. Listings do not carry the
+// metadata, so the size is derived from the size of the stored object. Objects stored before
+// encryption was enabled keep their size when no encrypted object can have it, others are listed
+// too small until a key rotation encrypts them.
+func (s *encryptedStorageService) ListObjects(ctx *gin.Context, opts ListOptions) (*ListResult, error) {
+	result, err := s.inner.ListObjects(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Objects {
+		if size := result.Objects[i].Size; encryptedSize(decryptedSize(size)) == size {
+			result.Objects[i].Size = decryptedSize(size)
+		}
+	}
+	return result, nil
+}
+
+// dataKey unwraps the data key of an object
+func (s *encryptedStorageService) dataKey(objectID string, encryption objectEncryption) (cipher.AEAD, error) {
+	dataKey, err := s.keys.unwrap(objectID, encryption.keyID, encryption.wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return newGCM(dataKey)
+}
+
+// rotate wraps the data keys of objects under older master keys with the current one and encrypts
+// objects stored before encryption was enabled. It returns how many objects were rewritten.
+func (s *encryptedStorageService) rotate(ctx *gin.Context) (int, error) {
+	rotated := 0
+	opts := ListOptions{Limit: maxListLimit}
+	for {
+		page, err := s.inner.ListObjects(ctx, opts)
+		if err != nil {
+			return rotated, err
+		}
+
+		for _, listed := range page.Objects {
+			rewritten, err := s.rotateObject(ctx, listed.ID)
+			if err != nil {
+				s.logger.Warn("Failed to rotate key of object", zap.String("object_id", listed.ID), zap.Error(err))
+				continue
+			}
+			if rewritten {
+				rotated++
+			}
+		}
+
+		if !page.IsTruncated {
+			return rotated, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+// rotateObject rewrites an object not encrypted with the current master key. The content of
+// encrypted objects is copied as it is, only their data key is wrapped again.
+func (s *encryptedStorageService) rotateObject(ctx *gin.Context, objectID string) (bool, error) {
+	logger := utils.GetLogger(ctx)
+
+	info, err := s.inner.StatObject(ctx, objectID)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	encryption, encrypted, err := encryptionOf(info)
+	if err != nil {
+		return false, err
+	}
+	if encrypted && encryption.keyID == s.keys.currentID() {
+		return false, nil
+	}
+
+	obj, info, err := s.inner.GetObject(ctx, objectID, GetOptions{})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer obj.Close()
+
+	// The object was read as it was stated, a newer version already uses the current key
+	opts := putOptionsOf(info)
+	opts.IfMatch = info.ETag
+	var data io.Reader = obj
+	size := info.Size
+	if encrypted {
+		dataKey, err := s.keys.unwrap(objectID, encryption.keyID, encryption.wrappedKey)
+		if err != nil {
+			return false, err
+		}
+		keyID, wrapped, err := s.keys.wrap(objectID, dataKey)
+		if err != nil {
+			return false, err
+		}
+		opts.UserMetadata = encryptionMetadata(opts.UserMetadata, keyID, wrapped, encryption.size)
+	} else {
+		if data, opts.UserMetadata, err = s.encrypt(objectID, obj, info.Size, opts.UserMetadata); err != nil {
+			return false, err
+		}
+		size = encryptedSize(info.Size)
+	}
+
+	err = s.inner.PutObject(ctx, objectID, data, size, opts)
+	if errors.Is(err, ErrPreconditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	logger.Info("Rotated key of object", zap.String("object_id", objectID), zap.Bool("was_encrypted", encrypted))
+	return true, nil
+}
+
+// objectEncryption is the encryption metadata of a stored object
+type objectEncryption struct {
+	keyID      string
+	wrappedKey []byte
+	// size is the size of the content before it was encrypted
+	size int64
+}
+
+// encryptionOf reads the encryption metadata of an object. It reports false for objects stored
+// before encryption was enabled.
+func encryptionOf(info ObjectInfo) (objectEncryption, bool, error) {
+	keyID, ok := info.UserMetadata[encryptionKeyIDMeta]
+	if !ok {
+		return objectEncryption{}, false, nil
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(info.UserMetadata[encryptionKeyMeta])
+	if err != nil {
+		return objectEncryption{}, false, fmt.Errorf("invalid wrapped data key: %w", err)
+	}
+	size, err := strconv.ParseInt(info.UserMetadata[encryptionSizeMeta], 10, 64)
+	if err != nil || size < 0 {
+		return objectEncryption{}, false, fmt.Errorf("invalid size of encrypted object: %q", info.UserMetadata[encryptionSizeMeta])
+	}
+	return objectEncryption{keyID: keyID, wrappedKey: wrapped, size: size}, true, nil
+}
+
+// objectInfo returns the metadata of the stored object as clients see it
+func (e objectEncryption) objectInfo(info ObjectInfo) ObjectInfo {
+	info.Size = e.size
+	info.UserMetadata = maps.Clone(info.UserMetadata)
+	for _, key := range []string{encryptionKeyIDMeta, encryptionKeyMeta, encryptionSizeMeta} {
+		delete(info.UserMetadata, key)
+	}
+	if len(info.UserMetadata) == 0 {
+		info.UserMetadata = nil
+	}
+	return info
+}
+
+// encryptionMetadata returns the user metadata with the encryption metadata, replacing entries
+// of the same name sent by the client
+func encryptionMetadata(userMetadata map[string]string, keyID string, wrappedKey []byte, size int64) map[string]string {
+	metadata := maps.Clone(userMetadata)
+	if metadata == nil {
+		metadata = make(map[string]string, 3)
+	}
+	metadata[encryptionKeyIDMeta] = keyID
+	metadata[encryptionKeyMeta] = base64.StdEncoding.EncodeToString(wrappedKey)
+	metadata[encryptionSizeMeta] = strconv.FormatInt(size, 10)
+	return metadata
+}
+
+// chunkCount returns the number of chunks of size bytes of content. Empty objects have a single
+// empty chunk, which reads open so removing all chunks is detected.
+func chunkCount(size int64) int64 {
+	return max((size+encryptionChunkSize-1)/encryptionChunkSize, 1)
+}
+
+// encryptedSize returns the size of the stored object for size bytes of content
+func encryptedSize(size int64) int64 {
+	return size + chunkCount(size)*encryptionTagSize
+}
+
+// decryptedSize returns the size of the content of a stored object of size bytes
+func decryptedSize(size int64) int64 {
+	chunks := max((size+encryptionChunkSize+encryptionTagSize-1)/(encryptionChunkSize+encryptionTagSize), 1)
+	return max(size-chunks*encryptionTagSize, 0)
+}
+
+// chunkNonce returns the nonce of a chunk. Data keys are never reused, so the index makes it unique.
+func chunkNonce(index int64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, uint64(index))
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptingReader seals the content read from source chunk by chunk
+type encryptingReader struct {
+	aead   cipher.AEAD
+	source io.Reader
+	// remaining is the content still to be read from source
+	remaining int64
+	index     int64
+	done      bool
+
+	plain  []byte
+	sealed []byte
+	// pending is the part of the sealed chunk not returned yet
+	pending []byte
+}
+
+func newEncryptingReader(aead cipher.AEAD, source io.Reader, size int64) *encryptingReader {
+	return &encryptingReader{
+		aead:      aead,
+		source:    source,
+		remaining: size,
+		plain:     make([]byte, encryptionChunkSize),
+		sealed:    make([]byte, 0, encryptionChunkSize+encryptionTagSize),
+	}
+}
+
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		n := min(r.remaining, encryptionChunkSize)
+		if _, err := io.ReadFull(r.source, r.plain[:n]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		r.remaining -= n
+		r.done = r.remaining == 0
+
+		r.pending = r.aead.Seal(r.sealed[:0], chunkNonce(r.index, r.done), r.plain[:n], nil)
+		r.index++
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// decryptingReader opens the chunks read from source, from chunk index up to and including last
+type decryptingReader struct {
+	aead   cipher.AEAD
+	source io.Reader
+	index  int64
+	last   int64
+	// final is the index of the final chunk of the object
+	final int64
+	// finalSize is the size of the final chunk as stored
+	finalSize int64
+
+	sealed []byte
+	plain  []byte
+	// pending is the part of the opened chunk not returned yet
+	pending []byte
+}
+
+func newDecryptingReader(aead cipher.AEAD, source io.Reader, first int64, last int64, size int64) *decryptingReader {
+	final := chunkCount(size) - 1
+	return &decryptingReader{
+		aead:      aead,
+		source:    source,
+		index:     first,
+		last:      last,
+		final:     final,
+		finalSize: size - final*encryptionChunkSize + encryptionTagSize,
+		sealed:    make([]byte, encryptionChunkSize+encryptionTagSize),
+		plain:     make([]byte, 0, encryptionChunkSize),
+	}
+}
+
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		if r.index > r.last {
+			return 0, io.EOF
+		}
+
+		sealed := r.sealed
+		if r.index == r.final {
+			sealed = r.sealed[:r.finalSize]
+		}
+		if _, err := io.ReadFull(r.source, sealed); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return 0, fmt.Errorf("encrypted object is truncated at chunk %d", r.index)
+			}
+			return 0, err
+		}
+
+		plain, err := r.aead.Open(r.plain[:0], chunkNonce(r.index, r.index == r.final), sealed, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt chunk %d of object: %w", r.index, err)
+		}
+		r.pending = plain
+		r.index++
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// decryptedObject is the decrypted content of an object, closing it closes the stored object
+type decryptedObject struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,296 @@
+package objectStorage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// writeKeyFile writes a keyfile with a new random key for every ID
+func writeKeyFile(t *testing.T, path string, current string, keys map[string][]byte, ids ...string) {
+	content := fmt.Sprintf("current: %s\nkeys:\n", current)
+	for _, id := range ids {
+		if keys[id] == nil {
+			keys[id] = make([]byte, encryptionKeySize)
+			rand.Read(keys[id])
+		}
+		content += fmt.Sprintf("  %s: %s\n", id, base64.StdEncoding.EncodeToString(keys[id]))
+	}
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+// newTestEncryptedService builds a service encrypting the objects kept in memory with a new key k1 written to path
+func newTestEncryptedService(t *testing.T, path string, keys map[string][]byte) (*encryptedStorageService, *memoryStorageService, *gin.Context) {
+	writeKeyFile(t, path, "k1", keys, "k1")
+	masterKeys, err := LoadMasterKeys(path)
+	require.NoError(t, err)
+
+	inner, ctx := newTestMemoryService(0)
+	return NewEncryptedStorageService(inner, masterKeys, zap.NewNop()), inner, ctx
+}
+
+// storedBytes returns the object as it is stored
+func storedBytes(t *testing.T, inner *memoryStorageService, objectID string) []byte {
+	object, err := inner.lookup(objectID, false)
+	require.NoError(t, err)
+	return object.data
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	service, inner, ctx := newTestEncryptedService(t, filepath.Join(t.TempDir(), "keys.yaml"), map[string][]byte{})
+	content := "This is a test object"
+
+	require.NoError(t, putString(ctx, service, "test123", content, PutOptions{ContentType: "text/plain", UserMetadata: map[string]string{"Owner": "sync"}}))
+
+	// Only ciphertext reaches the storage
+	stored := storedBytes(t, inner, "test123")
+	assert.Len(t, stored, len(content)+encryptionTagSize)
+	assert.NotContains(t, string(stored), "test object")
+
+	assert.Equal(t, content, readString(t, ctx, service, "test123", GetOptions{}))
+	info, err := service.StatObject(ctx, "test123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, map[string]string{"Owner": "sync"}, info.UserMetadata)
+
+	list, err := service.ListObjects(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Objects, 1)
+	assert.Equal(t, int64(len(content)), list.Objects[0].Size)
+
+	// Empty objects are encrypted as well
+	require.NoError(t, putString(ctx, service, "empty", "", PutOptions{}))
+	assert.Len(t, storedBytes(t, inner, "empty"), encryptionTagSize)
+	assert.Equal(t, "", readString(t, ctx, service, "empty", GetOptions{}))
+
+	// Each object has its own data key
+	require.NoError(t, putString(ctx, service, "copy", content, PutOptions{}))
+	assert.NotEqual(t, stored, storedBytes(t, inner, "copy"))
+
+	require.NoError(t, service.DeleteObject(ctx, "test123"))
+	_, err = service.StatObject(ctx, "test123")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestEncryptionRanges(t *testing.T) {
+	service, _, ctx := newTestEncryptedService(t, filepath.Join(t.TempDir(), "keys.yaml"), map[string][]byte{})
+	content := make([]byte, 3*encryptionChunkSize+1000)
+	rand.Read(content)
+	require.NoError(t, service.PutObject(ctx, "large", bytes.NewReader(content), int64(len(content)), PutOptions{}))
+
+	size := int64(len(content))
+	testCases := []struct {
+		name  string
+		start int64
+		end   int64
+	}{
+		{name: "Within First Chunk", start: 10, end: 13},
+		{name: "Across Chunks", start: encryptionChunkSize - 5, end: 2*encryptionChunkSize + 5},
+		{name: "Chunk Boundary", start: encryptionChunkSize, end: 2*encryptionChunkSize - 1},
+		{name: "Final Chunk", start: size - 10, end: size - 1},
+		{name: "Past The End", start: size - 10, end: size + 100},
+		{name: "Whole Object", start: 0, end: size - 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj, info, err := service.GetObject(ctx, "large", GetOptions{Range: &ByteRange{Start: tc.start, End: tc.end}})
+			require.NoError(t, err)
+			data, err := io.ReadAll(obj)
+			obj.Close()
+			require.NoError(t, err)
+
+			expected := content[tc.start:min(tc.end+1, size)]
+			assert.Equal(t, expected, data)
+			assert.Equal(t, int64(len(expected)), info.Size)
+		})
+	}
+
+	_, _, err := service.GetObject(ctx, "large", GetOptions{Range: &ByteRange{Start: size, End: size + 1}})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	require.NoError(t, putString(ctx, service, "empty", "", PutOptions{}))
+	_, _, err = service.GetObject(ctx, "empty", GetOptions{Range: &ByteRange{Start: 0, End: 0}})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	assert.Equal(t, content, []byte(readString(t, ctx, service, "large", GetOptions{})))
+}
+
+func TestEncryptionTampering(t *testing.T) {
+	service, inner, ctx := newTestEncryptedService(t, filepath.Join(t.TempDir(), "keys.yaml"), map[string][]byte{})
+	content := strings.Repeat("secret", encryptionChunkSize/3)
+	require.NoError(t, putString(ctx, service, "victim", content, PutOptions{}))
+	require.NoError(t, putString(ctx, service, "other", content, PutOptions{}))
+	victim, _ := inner.StatObject(ctx, "victim")
+	stored := storedBytes(t, inner, "victim")
+
+	read := func() error {
+		obj, _, err := service.GetObject(ctx, "victim", GetOptions{})
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		_, err = io.ReadAll(obj)
+		return err
+	}
+
+	testCases := []struct {
+		name    string
+		data    []byte
+		options func(opts *PutOptions)
+	}{
+		{name: "Modified Byte", data: append([]byte{stored[0] ^ 1}, stored[1:]...)},
+		{name: "Dropped Final Chunk", data: stored[:encryptionChunkSize+encryptionTagSize], options: func(opts *PutOptions) {
+			opts.UserMetadata[encryptionSizeMeta] = fmt.Sprint(encryptionChunkSize)
+		}},
+		{name: "Truncated", data: stored[:len(stored)-1]},
+		{name: "Swapped Chunks", data: append(append([]byte{}, stored[encryptionChunkSize+encryptionTagSize:]...), stored[:encryptionChunkSize+encryptionTagSize]...)},
+		{name: "Key Of Other Object", data: stored, options: func(opts *PutOptions) {
+			other, _ := inner.StatObject(ctx, "other")
+			opts.UserMetadata[encryptionKeyMeta] = other.UserMetadata[encryptionKeyMeta]
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := putOptionsOf(victim)
+			opts.UserMetadata = maps.Clone(victim.UserMetadata)
+			if tc.options != nil {
+				tc.options(&opts)
+			}
+			require.NoError(t, inner.PutObject(ctx, "victim", bytes.NewReader(tc.data), int64(len(tc.data)), opts))
+
+			assert.Error(t, read())
+		})
+	}
+
+	// The single chunk of an empty object is authenticated although no content is read
+	require.NoError(t, putString(ctx, service, "empty", "", PutOptions{}))
+	empty, _ := inner.StatObject(ctx, "empty")
+	sealed := storedBytes(t, inner, "empty")
+	for _, data := range [][]byte{{}, append([]byte{sealed[0] ^ 1}, sealed[1:]...)} {
+		require.NoError(t, inner.PutObject(ctx, "empty", bytes.NewReader(data), int64(len(data)), putOptionsOf(empty)))
+		_, _, err := service.GetObject(ctx, "empty", GetOptions{})
+		assert.Error(t, err)
+	}
+}
+
+// countingStorage counts the objects read from the storage it wraps
+type countingStorage struct {
+	ObjectStorage
+	gets int
+}
+
+func (s *countingStorage) GetObject(ctx *gin.Context, objectID string, opts GetOptions) (io.ReadCloser, ObjectInfo, error) {
+	s.gets++
+	return s.ObjectStorage.GetObject(ctx, objectID, opts)
+}
+
+func TestEncryptionPlaintextObjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	writeKeyFile(t, path, "k1", map[string][]byte{}, "k1")
+	keys, err := LoadMasterKeys(path)
+	require.NoError(t, err)
+	memory, ctx := newTestMemoryService(0)
+	inner := &countingStorage{ObjectStorage: memory}
+	service := NewEncryptedStorageService(inner, keys, zap.NewNop())
+
+	// Objects stored before encryption was enabled are read once with the requested range
+	content := "written in plaintext"
+	require.NoError(t, putString(ctx, memory, "plain", content, PutOptions{}))
+	assert.Equal(t, "plain", readString(t, ctx, service, "plain", GetOptions{Range: &ByteRange{Start: 11, End: 15}}))
+	assert.Equal(t, 1, inner.gets)
+	require.NoError(t, putString(ctx, service, "encrypted", content, PutOptions{}))
+	assert.Equal(t, "plain", readString(t, ctx, service, "encrypted", GetOptions{Range: &ByteRange{Start: 11, End: 15}}))
+	assert.Equal(t, 2, inner.gets)
+
+	// Listings keep sizes no encrypted object can have, and can only guess the others
+	require.NoError(t, putString(ctx, memory, "short", "short", PutOptions{}))
+	list, err := service.ListObjects(ctx, ListOptions{})
+	require.NoError(t, err)
+	sizes := map[string]int64{}
+	for _, object := range list.Objects {
+		sizes[object.ID] = object.Size
+	}
+	assert.Equal(t, map[string]int64{"encrypted": 20, "plain": 4, "short": 5}, sizes)
+}
+
+func TestEncryptionKeyRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	keys := map[string][]byte{}
+	service, inner, ctx := newTestEncryptedService(t, path, keys)
+	require.NoError(t, putString(ctx, service, "old", "written with k1", PutOptions{UserMetadata: map[string]string{"Owner": "sync"}}))
+	// Objects stored before encryption was enabled are served as they are
+	require.NoError(t, putString(ctx, inner, "plain", "written in plaintext", PutOptions{}))
+	assert.Equal(t, "plain", readString(t, ctx, service, "plain", GetOptions{Range: &ByteRange{Start: 11, End: 15}}))
+
+	// A new current key is added while the old one is kept
+	writeKeyFile(t, path, "k2", keys, "k1", "k2")
+	rotatedKeys, err := LoadMasterKeys(path)
+	require.NoError(t, err)
+	rotatedService := NewEncryptedStorageService(inner, rotatedKeys, zap.NewNop())
+
+	require.NoError(t, putString(ctx, rotatedService, "new", "written with k2", PutOptions{}))
+	assert.Equal(t, "written with k1", readString(t, ctx, rotatedService, "old", GetOptions{}))
+
+	// The service with the old keyfile picks up the new key when it meets it
+	assert.Equal(t, "written with k2", readString(t, ctx, service, "new", GetOptions{}))
+
+	rotated, err := rotatedService.rotate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rotated)
+	for _, id := range []string{"old", "new", "plain"} {
+		info, err := inner.StatObject(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "k2", info.UserMetadata[encryptionKeyIDMeta], id)
+	}
+	assert.NotContains(t, string(storedBytes(t, inner, "plain")), "plaintext")
+
+	// Once every object is rotated the old key can be removed
+	writeKeyFile(t, path, "k2", keys, "k2")
+	withoutOldKey, err := LoadMasterKeys(path)
+	require.NoError(t, err)
+	finalService := NewEncryptedStorageService(inner, withoutOldKey, zap.NewNop())
+	assert.Equal(t, "written with k1", readString(t, ctx, finalService, "old", GetOptions{}))
+	assert.Equal(t, "written in plaintext", readString(t, ctx, finalService, "plain", GetOptions{}))
+	info, err := finalService.StatObject(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Owner": "sync"}, info.UserMetadata)
+
+	rotated, err = finalService.rotate(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, rotated)
+}
+
+func TestLoadMasterKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+
+	writeKeyFile(t, path, "k2", map[string][]byte{}, "k1")
+	_, err := LoadMasterKeys(path)
+	assert.EqualError(t, err, `key file does not hold the current key "k2"`)
+
+	require.NoError(t, os.WriteFile(path, []byte("current: k1\nkeys:\n  k1: c2hvcnQ=\n"), 0o600))
+	_, err = LoadMasterKeys(path)
+	assert.EqualError(t, err, `master key "k1" must be 32 bytes encoded in base64`)
+
+	_, err = LoadMasterKeys(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestEncryptedSize(t *testing.T) {
+	for _, size := range []int64{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 5*encryptionChunkSize + 17} {
+		assert.Equal(t, size, decryptedSize(encryptedSize(size)), size)
+	}
+	assert.Equal(t, int64(2*encryptionChunkSize+2*encryptionTagSize), encryptedSize(2*encryptionChunkSize))
+}
@@ -0,0 +1,54 @@
+package objectStorage
+
+import "errors"
+
+// Kinds of storage errors. Backends return or wrap one of these so callers can tell failures
+// apart with errors.Is instead of comparing error messages.
+var (
+	// ErrNotFound is returned when an object does not exist
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidID is returned for object IDs that do not meet the requirements
+	ErrInvalidID = errors.New("invalid object ID")
+	// ErrConflict is returned when an operation conflicts with one that is already running
+	ErrConflict = errors.New("conflict")
+	// ErrUnavailable is returned when not enough storage nodes can be reached
+	ErrUnavailable = errors.New("storage unavailable")
+	// ErrTooLarge is returned for objects exceeding the size the backend accepts
+	ErrTooLarge = errors.New("object too large")
+	// ErrPreconditionFailed is returned when a conditional write does not hold
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrInvalidRange is returned for byte ranges the object cannot satisfy
+	ErrInvalidRange = errors.New("invalid range")
+)
+
+// Error is a storage error of a given kind. Message describes the failure and Err is the
+// underlying backend error, if any. errors.Is matches an Error against its kind.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+// NewError returns an error of the given kind wrapping err, which may be nil
+func NewError(kind error, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+func (e *Error) Error() string {
+	message := e.Message
+	if message == "" {
+		message = e.Kind.Error()
+	}
+	if e.Err != nil {
+		return message + ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,63 @@
+package objectStorage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	testCases := []struct {
+		name            string
+		err             error
+		expectedKind    error
+		expectedMessage string
+	}{
+		{
+			name:            "Invalid ID",
+			err:             validateObjectID("not-valid"),
+			expectedKind:    ErrInvalidID,
+			expectedMessage: "object ID must contain only alphanumeric characters",
+		},
+		{
+			name:            "Wrapped cause",
+			err:             fmt.Errorf("get: %w", NewError(ErrUnavailable, "write quorum not reached (1/2)", cause)),
+			expectedKind:    ErrUnavailable,
+			expectedMessage: "get: write quorum not reached (1/2): connection refused",
+		},
+		{
+			name:            "Kind as message",
+			err:             NewError(ErrTooLarge, "", nil),
+			expectedKind:    ErrTooLarge,
+			expectedMessage: "object too large",
+		},
+		{
+			name:            "Rebalance running",
+			err:             ErrRebalanceRunning,
+			expectedKind:    ErrConflict,
+			expectedMessage: "rebalance already running",
+		},
+		{
+			name:            "Open circuit breaker",
+			err:             &NodeUnavailableError{Node: "node-1", RetryAfter: time.Second},
+			expectedKind:    ErrUnavailable,
+			expectedMessage: "node node-1 unavailable, retry after 1s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, tc.err, tc.expectedKind)
+			assert.NotErrorIs(t, tc.err, ErrNotFound)
+			assert.EqualError(t, tc.err, tc.expectedMessage)
+		})
+	}
+
+	// The backend error stays reachable for callers that need the details
+	assert.ErrorIs(t, NewError(ErrUnavailable, "", cause), cause)
+}
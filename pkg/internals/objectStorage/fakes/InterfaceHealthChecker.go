@@ -0,0 +1,102 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+type InterfaceHealthChecker struct {
+	NodeHealthStub        func() []objectStorage.NodeHealth
+	nodeHealthMutex       sync.RWMutex
+	nodeHealthArgsForCall []struct {
+	}
+	nodeHealthReturns struct {
+		result1 []objectStorage.NodeHealth
+	}
+	nodeHealthReturnsOnCall map[int]struct {
+		result1 []objectStorage.NodeHealth
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *InterfaceHealthChecker) NodeHealth() []objectStorage.NodeHealth {
+	fake.nodeHealthMutex.Lock()
+	ret, specificReturn := fake.nodeHealthReturnsOnCall[len(fake.nodeHealthArgsForCall)]
+	fake.nodeHealthArgsForCall = append(fake.nodeHealthArgsForCall, struct {
+	}{})
+	stub := fake.NodeHealthStub
+	fakeReturns := fake.nodeHealthReturns
+	fake.recordInvocation("NodeHealth", []interface{}{})
+	fake.nodeHealthMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *InterfaceHealthChecker) NodeHealthCallCount() int {
+	fake.nodeHealthMutex.RLock()
+	defer fake.nodeHealthMutex.RUnlock()
+	return len(fake.nodeHealthArgsForCall)
+}
+
+func (fake *InterfaceHealthChecker) NodeHealthCalls(stub func() []objectStorage.NodeHealth) {
+	fake.nodeHealthMutex.Lock()
+	defer fake.nodeHealthMutex.Unlock()
+	fake.NodeHealthStub = stub
+}
+
+func (fake *InterfaceHealthChecker) NodeHealthReturns(result1 []objectStorage.NodeHealth) {
+	fake.nodeHealthMutex.Lock()
+	defer fake.nodeHealthMutex.Unlock()
+	fake.NodeHealthStub = nil
+	fake.nodeHealthReturns = struct {
+		result1 []objectStorage.NodeHealth
+	}{result1}
+}
+
+func (fake *InterfaceHealthChecker) NodeHealthReturnsOnCall(i int, result1 []objectStorage.NodeHealth) {
+	fake.nodeHealthMutex.Lock()
+	defer fake.nodeHealthMutex.Unlock()
+	fake.NodeHealthStub = nil
+	if fake.nodeHealthReturnsOnCall == nil {
+		fake.nodeHealthReturnsOnCall = make(map[int]struct {
+			result1 []objectStorage.NodeHealth
+		})
+	}
+	fake.nodeHealthReturnsOnCall[i] = struct {
+		result1 []objectStorage.NodeHealth
+	}{result1}
+}
+
+func (fake *InterfaceHealthChecker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.nodeHealthMutex.RLock()
+	defer fake.nodeHealthMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *InterfaceHealthChecker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ objectStorage.HealthChecker = new(InterfaceHealthChecker)
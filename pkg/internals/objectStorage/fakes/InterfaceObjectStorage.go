@@ -10,27 +10,57 @@ import (
 )
 
 type InterfaceObjectStorage struct {
-	GetObjectStub        func(*gin.Context, string) (io.ReadCloser, error)
+	DeleteObjectStub        func(*gin.Context, string) error
+	deleteObjectMutex       sync.RWMutex
+	deleteObjectArgsForCall []struct {
+		arg1 *gin.Context
+		arg2 string
+	}
+	deleteObjectReturns struct {
+		result1 error
+	}
+	deleteObjectReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetObjectStub        func(*gin.Context, string, objectStorage.GetOptions) (io.ReadCloser, objectStorage.ObjectInfo, error)
 	getObjectMutex       sync.RWMutex
 	getObjectArgsForCall []struct {
 		arg1 *gin.Context
 		arg2 string
+		arg3 objectStorage.GetOptions
 	}
 	getObjectReturns struct {
 		result1 io.ReadCloser
-		result2 error
+		result2 objectStorage.ObjectInfo
+		result3 error
 	}
 	getObjectReturnsOnCall map[int]struct {
 		result1 io.ReadCloser
+		result2 objectStorage.ObjectInfo
+		result3 error
+	}
+	ListObjectsStub        func(*gin.Context, objectStorage.ListOptions) (*objectStorage.ListResult, error)
+	listObjectsMutex       sync.RWMutex
+	listObjectsArgsForCall []struct {
+		arg1 *gin.Context
+		arg2 objectStorage.ListOptions
+	}
+	listObjectsReturns struct {
+		result1 *objectStorage.ListResult
 		result2 error
 	}
-	PutObjectStub        func(*gin.Context, string, io.Reader, int64) error
+	listObjectsReturnsOnCall map[int]struct {
+		result1 *objectStorage.ListResult
+		result2 error
+	}
+	PutObjectStub        func(*gin.Context, string, io.Reader, int64, objectStorage.PutOptions) error
 	putObjectMutex       sync.RWMutex
 	putObjectArgsForCall []struct {
 		arg1 *gin.Context
 		arg2 string
 		arg3 io.Reader
 		arg4 int64
+		arg5 objectStorage.PutOptions
 	}
 	putObjectReturns struct {
 		result1 error
@@ -38,28 +68,105 @@ type InterfaceObjectStorage struct {
 	putObjectReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StatObjectStub        func(*gin.Context, string) (objectStorage.ObjectInfo, error)
+	statObjectMutex       sync.RWMutex
+	statObjectArgsForCall []struct {
+		arg1 *gin.Context
+		arg2 string
+	}
+	statObjectReturns struct {
+		result1 objectStorage.ObjectInfo
+		result2 error
+	}
+	statObjectReturnsOnCall map[int]struct {
+		result1 objectStorage.ObjectInfo
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *InterfaceObjectStorage) GetObject(arg1 *gin.Context, arg2 string) (io.ReadCloser, error) {
+func (fake *InterfaceObjectStorage) DeleteObject(arg1 *gin.Context, arg2 string) error {
+	fake.deleteObjectMutex.Lock()
+	ret, specificReturn := fake.deleteObjectReturnsOnCall[len(fake.deleteObjectArgsForCall)]
+	fake.deleteObjectArgsForCall = append(fake.deleteObjectArgsForCall, struct {
+		arg1 *gin.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteObjectStub
+	fakeReturns := fake.deleteObjectReturns
+	fake.recordInvocation("DeleteObject", []interface{}{arg1, arg2})
+	fake.deleteObjectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *InterfaceObjectStorage) DeleteObjectCallCount() int {
+	fake.deleteObjectMutex.RLock()
+	defer fake.deleteObjectMutex.RUnlock()
+	return len(fake.deleteObjectArgsForCall)
+}
+
+func (fake *InterfaceObjectStorage) DeleteObjectCalls(stub func(*gin.Context, string) error) {
+	fake.deleteObjectMutex.Lock()
+	defer fake.deleteObjectMutex.Unlock()
+	fake.DeleteObjectStub = stub
+}
+
+func (fake *InterfaceObjectStorage) DeleteObjectArgsForCall(i int) (*gin.Context, string) {
+	fake.deleteObjectMutex.RLock()
+	defer fake.deleteObjectMutex.RUnlock()
+	argsForCall := fake.deleteObjectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *InterfaceObjectStorage) DeleteObjectReturns(result1 error) {
+	fake.deleteObjectMutex.Lock()
+	defer fake.deleteObjectMutex.Unlock()
+	fake.DeleteObjectStub = nil
+	fake.deleteObjectReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *InterfaceObjectStorage) DeleteObjectReturnsOnCall(i int, result1 error) {
+	fake.deleteObjectMutex.Lock()
+	defer fake.deleteObjectMutex.Unlock()
+	fake.DeleteObjectStub = nil
+	if fake.deleteObjectReturnsOnCall == nil {
+		fake.deleteObjectReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteObjectReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *InterfaceObjectStorage) GetObject(arg1 *gin.Context, arg2 string, arg3 objectStorage.GetOptions) (io.ReadCloser, objectStorage.ObjectInfo, error) {
 	fake.getObjectMutex.Lock()
 	ret, specificReturn := fake.getObjectReturnsOnCall[len(fake.getObjectArgsForCall)]
 	fake.getObjectArgsForCall = append(fake.getObjectArgsForCall, struct {
 		arg1 *gin.Context
 		arg2 string
-	}{arg1, arg2})
+		arg3 objectStorage.GetOptions
+	}{arg1, arg2, arg3})
 	stub := fake.GetObjectStub
 	fakeReturns := fake.getObjectReturns
-	fake.recordInvocation("GetObject", []interface{}{arg1, arg2})
+	fake.recordInvocation("GetObject", []interface{}{arg1, arg2, arg3})
 	fake.getObjectMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1, ret.result2, ret.result3
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
 }
 
 func (fake *InterfaceObjectStorage) GetObjectCallCount() int {
@@ -68,46 +175,114 @@ func (fake *InterfaceObjectStorage) GetObjectCallCount() int {
 	return len(fake.getObjectArgsForCall)
 }
 
-func (fake *InterfaceObjectStorage) GetObjectCalls(stub func(*gin.Context, string) (io.ReadCloser, error)) {
+func (fake *InterfaceObjectStorage) GetObjectCalls(stub func(*gin.Context, string, objectStorage.GetOptions) (io.ReadCloser, objectStorage.ObjectInfo, error)) {
 	fake.getObjectMutex.Lock()
 	defer fake.getObjectMutex.Unlock()
 	fake.GetObjectStub = stub
 }
 
-func (fake *InterfaceObjectStorage) GetObjectArgsForCall(i int) (*gin.Context, string) {
+func (fake *InterfaceObjectStorage) GetObjectArgsForCall(i int) (*gin.Context, string, objectStorage.GetOptions) {
 	fake.getObjectMutex.RLock()
 	defer fake.getObjectMutex.RUnlock()
 	argsForCall := fake.getObjectArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
-func (fake *InterfaceObjectStorage) GetObjectReturns(result1 io.ReadCloser, result2 error) {
+func (fake *InterfaceObjectStorage) GetObjectReturns(result1 io.ReadCloser, result2 objectStorage.ObjectInfo, result3 error) {
 	fake.getObjectMutex.Lock()
 	defer fake.getObjectMutex.Unlock()
 	fake.GetObjectStub = nil
 	fake.getObjectReturns = struct {
 		result1 io.ReadCloser
-		result2 error
-	}{result1, result2}
+		result2 objectStorage.ObjectInfo
+		result3 error
+	}{result1, result2, result3}
 }
 
-func (fake *InterfaceObjectStorage) GetObjectReturnsOnCall(i int, result1 io.ReadCloser, result2 error) {
+func (fake *InterfaceObjectStorage) GetObjectReturnsOnCall(i int, result1 io.ReadCloser, result2 objectStorage.ObjectInfo, result3 error) {
 	fake.getObjectMutex.Lock()
 	defer fake.getObjectMutex.Unlock()
 	fake.GetObjectStub = nil
 	if fake.getObjectReturnsOnCall == nil {
 		fake.getObjectReturnsOnCall = make(map[int]struct {
 			result1 io.ReadCloser
-			result2 error
+			result2 objectStorage.ObjectInfo
+			result3 error
 		})
 	}
 	fake.getObjectReturnsOnCall[i] = struct {
 		result1 io.ReadCloser
+		result2 objectStorage.ObjectInfo
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *InterfaceObjectStorage) ListObjects(arg1 *gin.Context, arg2 objectStorage.ListOptions) (*objectStorage.ListResult, error) {
+	fake.listObjectsMutex.Lock()
+	ret, specificReturn := fake.listObjectsReturnsOnCall[len(fake.listObjectsArgsForCall)]
+	fake.listObjectsArgsForCall = append(fake.listObjectsArgsForCall, struct {
+		arg1 *gin.Context
+		arg2 objectStorage.ListOptions
+	}{arg1, arg2})
+	stub := fake.ListObjectsStub
+	fakeReturns := fake.listObjectsReturns
+	fake.recordInvocation("ListObjects", []interface{}{arg1, arg2})
+	fake.listObjectsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *InterfaceObjectStorage) ListObjectsCallCount() int {
+	fake.listObjectsMutex.RLock()
+	defer fake.listObjectsMutex.RUnlock()
+	return len(fake.listObjectsArgsForCall)
+}
+
+func (fake *InterfaceObjectStorage) ListObjectsCalls(stub func(*gin.Context, objectStorage.ListOptions) (*objectStorage.ListResult, error)) {
+	fake.listObjectsMutex.Lock()
+	defer fake.listObjectsMutex.Unlock()
+	fake.ListObjectsStub = stub
+}
+
+func (fake *InterfaceObjectStorage) ListObjectsArgsForCall(i int) (*gin.Context, objectStorage.ListOptions) {
+	fake.listObjectsMutex.RLock()
+	defer fake.listObjectsMutex.RUnlock()
+	argsForCall := fake.listObjectsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *InterfaceObjectStorage) ListObjectsReturns(result1 *objectStorage.ListResult, result2 error) {
+	fake.listObjectsMutex.Lock()
+	defer fake.listObjectsMutex.Unlock()
+	fake.ListObjectsStub = nil
+	fake.listObjectsReturns = struct {
+		result1 *objectStorage.ListResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *InterfaceObjectStorage) ListObjectsReturnsOnCall(i int, result1 *objectStorage.ListResult, result2 error) {
+	fake.listObjectsMutex.Lock()
+	defer fake.listObjectsMutex.Unlock()
+	fake.ListObjectsStub = nil
+	if fake.listObjectsReturnsOnCall == nil {
+		fake.listObjectsReturnsOnCall = make(map[int]struct {
+			result1 *objectStorage.ListResult
+			result2 error
+		})
+	}
+	fake.listObjectsReturnsOnCall[i] = struct {
+		result1 *objectStorage.ListResult
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *InterfaceObjectStorage) PutObject(arg1 *gin.Context, arg2 string, arg3 io.Reader, arg4 int64) error {
+func (fake *InterfaceObjectStorage) PutObject(arg1 *gin.Context, arg2 string, arg3 io.Reader, arg4 int64, arg5 objectStorage.PutOptions) error {
 	fake.putObjectMutex.Lock()
 	ret, specificReturn := fake.putObjectReturnsOnCall[len(fake.putObjectArgsForCall)]
 	fake.putObjectArgsForCall = append(fake.putObjectArgsForCall, struct {
@@ -115,13 +290,14 @@ func (fake *InterfaceObjectStorage) PutObject(arg1 *gin.Context, arg2 string, ar
 		arg2 string
 		arg3 io.Reader
 		arg4 int64
-	}{arg1, arg2, arg3, arg4})
+		arg5 objectStorage.PutOptions
+	}{arg1, arg2, arg3, arg4, arg5})
 	stub := fake.PutObjectStub
 	fakeReturns := fake.putObjectReturns
-	fake.recordInvocation("PutObject", []interface{}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("PutObject", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.putObjectMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1
@@ -135,17 +311,17 @@ func (fake *InterfaceObjectStorage) PutObjectCallCount() int {
 	return len(fake.putObjectArgsForCall)
 }
 
-func (fake *InterfaceObjectStorage) PutObjectCalls(stub func(*gin.Context, string, io.Reader, int64) error) {
+func (fake *InterfaceObjectStorage) PutObjectCalls(stub func(*gin.Context, string, io.Reader, int64, objectStorage.PutOptions) error) {
 	fake.putObjectMutex.Lock()
 	defer fake.putObjectMutex.Unlock()
 	fake.PutObjectStub = stub
 }
 
-func (fake *InterfaceObjectStorage) PutObjectArgsForCall(i int) (*gin.Context, string, io.Reader, int64) {
+func (fake *InterfaceObjectStorage) PutObjectArgsForCall(i int) (*gin.Context, string, io.Reader, int64, objectStorage.PutOptions) {
 	fake.putObjectMutex.RLock()
 	defer fake.putObjectMutex.RUnlock()
 	argsForCall := fake.putObjectArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *InterfaceObjectStorage) PutObjectReturns(result1 error) {
@@ -171,13 +347,84 @@ func (fake *InterfaceObjectStorage) PutObjectReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *InterfaceObjectStorage) StatObject(arg1 *gin.Context, arg2 string) (objectStorage.ObjectInfo, error) {
+	fake.statObjectMutex.Lock()
+	ret, specificReturn := fake.statObjectReturnsOnCall[len(fake.statObjectArgsForCall)]
+	fake.statObjectArgsForCall = append(fake.statObjectArgsForCall, struct {
+		arg1 *gin.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.StatObjectStub
+	fakeReturns := fake.statObjectReturns
+	fake.recordInvocation("StatObject", []interface{}{arg1, arg2})
+	fake.statObjectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *InterfaceObjectStorage) StatObjectCallCount() int {
+	fake.statObjectMutex.RLock()
+	defer fake.statObjectMutex.RUnlock()
+	return len(fake.statObjectArgsForCall)
+}
+
+func (fake *InterfaceObjectStorage) StatObjectCalls(stub func(*gin.Context, string) (objectStorage.ObjectInfo, error)) {
+	fake.statObjectMutex.Lock()
+	defer fake.statObjectMutex.Unlock()
+	fake.StatObjectStub = stub
+}
+
+func (fake *InterfaceObjectStorage) StatObjectArgsForCall(i int) (*gin.Context, string) {
+	fake.statObjectMutex.RLock()
+	defer fake.statObjectMutex.RUnlock()
+	argsForCall := fake.statObjectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *InterfaceObjectStorage) StatObjectReturns(result1 objectStorage.ObjectInfo, result2 error) {
+	fake.statObjectMutex.Lock()
+	defer fake.statObjectMutex.Unlock()
+	fake.StatObjectStub = nil
+	fake.statObjectReturns = struct {
+		result1 objectStorage.ObjectInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *InterfaceObjectStorage) StatObjectReturnsOnCall(i int, result1 objectStorage.ObjectInfo, result2 error) {
+	fake.statObjectMutex.Lock()
+	defer fake.statObjectMutex.Unlock()
+	fake.StatObjectStub = nil
+	if fake.statObjectReturnsOnCall == nil {
+		fake.statObjectReturnsOnCall = make(map[int]struct {
+			result1 objectStorage.ObjectInfo
+			result2 error
+		})
+	}
+	fake.statObjectReturnsOnCall[i] = struct {
+		result1 objectStorage.ObjectInfo
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *InterfaceObjectStorage) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.deleteObjectMutex.RLock()
+	defer fake.deleteObjectMutex.RUnlock()
 	fake.getObjectMutex.RLock()
 	defer fake.getObjectMutex.RUnlock()
+	fake.listObjectsMutex.RLock()
+	defer fake.listObjectsMutex.RUnlock()
 	fake.putObjectMutex.RLock()
 	defer fake.putObjectMutex.RUnlock()
+	fake.statObjectMutex.RLock()
+	defer fake.statObjectMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
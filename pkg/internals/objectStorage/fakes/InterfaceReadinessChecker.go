@@ -0,0 +1,102 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+type InterfaceReadinessChecker struct {
+	ReadinessStub        func() objectStorage.Readiness
+	readinessMutex       sync.RWMutex
+	readinessArgsForCall []struct {
+	}
+	readinessReturns struct {
+		result1 objectStorage.Readiness
+	}
+	readinessReturnsOnCall map[int]struct {
+		result1 objectStorage.Readiness
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *InterfaceReadinessChecker) Readiness() objectStorage.Readiness {
+	fake.readinessMutex.Lock()
+	ret, specificReturn := fake.readinessReturnsOnCall[len(fake.readinessArgsForCall)]
+	fake.readinessArgsForCall = append(fake.readinessArgsForCall, struct {
+	}{})
+	stub := fake.ReadinessStub
+	fakeReturns := fake.readinessReturns
+	fake.recordInvocation("Readiness", []interface{}{})
+	fake.readinessMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *InterfaceReadinessChecker) ReadinessCallCount() int {
+	fake.readinessMutex.RLock()
+	defer fake.readinessMutex.RUnlock()
+	return len(fake.readinessArgsForCall)
+}
+
+func (fake *InterfaceReadinessChecker) ReadinessCalls(stub func() objectStorage.Readiness) {
+	fake.readinessMutex.Lock()
+	defer fake.readinessMutex.Unlock()
+	fake.ReadinessStub = stub
+}
+
+func (fake *InterfaceReadinessChecker) ReadinessReturns(result1 objectStorage.Readiness) {
+	fake.readinessMutex.Lock()
+	defer fake.readinessMutex.Unlock()
+	fake.ReadinessStub = nil
+	fake.readinessReturns = struct {
+		result1 objectStorage.Readiness
+	}{result1}
+}
+
+func (fake *InterfaceReadinessChecker) ReadinessReturnsOnCall(i int, result1 objectStorage.Readiness) {
+	fake.readinessMutex.Lock()
+	defer fake.readinessMutex.Unlock()
+	fake.ReadinessStub = nil
+	if fake.readinessReturnsOnCall == nil {
+		fake.readinessReturnsOnCall = make(map[int]struct {
+			result1 objectStorage.Readiness
+		})
+	}
+	fake.readinessReturnsOnCall[i] = struct {
+		result1 objectStorage.Readiness
+	}{result1}
+}
+
+func (fake *InterfaceReadinessChecker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.readinessMutex.RLock()
+	defer fake.readinessMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *InterfaceReadinessChecker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ objectStorage.ReadinessChecker = new(InterfaceReadinessChecker)
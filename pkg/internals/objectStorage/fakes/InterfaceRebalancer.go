@@ -0,0 +1,271 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+type InterfaceRebalancer struct {
+	SetThrottleStub        func(objectStorage.RebalanceThrottle) error
+	setThrottleMutex       sync.RWMutex
+	setThrottleArgsForCall []struct {
+		arg1 objectStorage.RebalanceThrottle
+	}
+	setThrottleReturns struct {
+		result1 error
+	}
+	setThrottleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StartStub        func() error
+	startMutex       sync.RWMutex
+	startArgsForCall []struct {
+	}
+	startReturns struct {
+		result1 error
+	}
+	startReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StatusStub        func() objectStorage.RebalanceStatus
+	statusMutex       sync.RWMutex
+	statusArgsForCall []struct {
+	}
+	statusReturns struct {
+		result1 objectStorage.RebalanceStatus
+	}
+	statusReturnsOnCall map[int]struct {
+		result1 objectStorage.RebalanceStatus
+	}
+	StopStub        func()
+	stopMutex       sync.RWMutex
+	stopArgsForCall []struct {
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *InterfaceRebalancer) SetThrottle(arg1 objectStorage.RebalanceThrottle) error {
+	fake.setThrottleMutex.Lock()
+	ret, specificReturn := fake.setThrottleReturnsOnCall[len(fake.setThrottleArgsForCall)]
+	fake.setThrottleArgsForCall = append(fake.setThrottleArgsForCall, struct {
+		arg1 objectStorage.RebalanceThrottle
+	}{arg1})
+	stub := fake.SetThrottleStub
+	fakeReturns := fake.setThrottleReturns
+	fake.recordInvocation("SetThrottle", []interface{}{arg1})
+	fake.setThrottleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *InterfaceRebalancer) SetThrottleCallCount() int {
+	fake.setThrottleMutex.RLock()
+	defer fake.setThrottleMutex.RUnlock()
+	return len(fake.setThrottleArgsForCall)
+}
+
+func (fake *InterfaceRebalancer) SetThrottleCalls(stub func(objectStorage.RebalanceThrottle) error) {
+	fake.setThrottleMutex.Lock()
+	defer fake.setThrottleMutex.Unlock()
+	fake.SetThrottleStub = stub
+}
+
+func (fake *InterfaceRebalancer) SetThrottleArgsForCall(i int) objectStorage.RebalanceThrottle {
+	fake.setThrottleMutex.RLock()
+	defer fake.setThrottleMutex.RUnlock()
+	argsForCall := fake.setThrottleArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *InterfaceRebalancer) SetThrottleReturns(result1 error) {
+	fake.setThrottleMutex.Lock()
+	defer fake.setThrottleMutex.Unlock()
+	fake.SetThrottleStub = nil
+	fake.setThrottleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *InterfaceRebalancer) SetThrottleReturnsOnCall(i int, result1 error) {
+	fake.setThrottleMutex.Lock()
+	defer fake.setThrottleMutex.Unlock()
+	fake.SetThrottleStub = nil
+	if fake.setThrottleReturnsOnCall == nil {
+		fake.setThrottleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setThrottleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *InterfaceRebalancer) Start() error {
+	fake.startMutex.Lock()
+	ret, specificReturn := fake.startReturnsOnCall[len(fake.startArgsForCall)]
+	fake.startArgsForCall = append(fake.startArgsForCall, struct {
+	}{})
+	stub := fake.StartStub
+	fakeReturns := fake.startReturns
+	fake.recordInvocation("Start", []interface{}{})
+	fake.startMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *InterfaceRebalancer) StartCallCount() int {
+	fake.startMutex.RLock()
+	defer fake.startMutex.RUnlock()
+	return len(fake.startArgsForCall)
+}
+
+func (fake *InterfaceRebalancer) StartCalls(stub func() error) {
+	fake.startMutex.Lock()
+	defer fake.startMutex.Unlock()
+	fake.StartStub = stub
+}
+
+func (fake *InterfaceRebalancer) StartReturns(result1 error) {
+	fake.startMutex.Lock()
+	defer fake.startMutex.Unlock()
+	fake.StartStub = nil
+	fake.startReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *InterfaceRebalancer) StartReturnsOnCall(i int, result1 error) {
+	fake.startMutex.Lock()
+	defer fake.startMutex.Unlock()
+	fake.StartStub = nil
+	if fake.startReturnsOnCall == nil {
+		fake.startReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.startReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *InterfaceRebalancer) Status() objectStorage.RebalanceStatus {
+	fake.statusMutex.Lock()
+	ret, specificReturn := fake.statusReturnsOnCall[len(fake.statusArgsForCall)]
+	fake.statusArgsForCall = append(fake.statusArgsForCall, struct {
+	}{})
+	stub := fake.StatusStub
+	fakeReturns := fake.statusReturns
+	fake.recordInvocation("Status", []interface{}{})
+	fake.statusMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *InterfaceRebalancer) StatusCallCount() int {
+	fake.statusMutex.RLock()
+	defer fake.statusMutex.RUnlock()
+	return len(fake.statusArgsForCall)
+}
+
+func (fake *InterfaceRebalancer) StatusCalls(stub func() objectStorage.RebalanceStatus) {
+	fake.statusMutex.Lock()
+	defer fake.statusMutex.Unlock()
+	fake.StatusStub = stub
+}
+
+func (fake *InterfaceRebalancer) StatusReturns(result1 objectStorage.RebalanceStatus) {
+	fake.statusMutex.Lock()
+	defer fake.statusMutex.Unlock()
+	fake.StatusStub = nil
+	fake.statusReturns = struct {
+		result1 objectStorage.RebalanceStatus
+	}{result1}
+}
+
+func (fake *InterfaceRebalancer) StatusReturnsOnCall(i int, result1 objectStorage.RebalanceStatus) {
+	fake.statusMutex.Lock()
+	defer fake.statusMutex.Unlock()
+	fake.StatusStub = nil
+	if fake.statusReturnsOnCall == nil {
+		fake.statusReturnsOnCall = make(map[int]struct {
+			result1 objectStorage.RebalanceStatus
+		})
+	}
+	fake.statusReturnsOnCall[i] = struct {
+		result1 objectStorage.RebalanceStatus
+	}{result1}
+}
+
+func (fake *InterfaceRebalancer) Stop() {
+	fake.stopMutex.Lock()
+	fake.stopArgsForCall = append(fake.stopArgsForCall, struct {
+	}{})
+	stub := fake.StopStub
+	fake.recordInvocation("Stop", []interface{}{})
+	fake.stopMutex.Unlock()
+	if stub != nil {
+		fake.StopStub()
+	}
+}
+
+func (fake *InterfaceRebalancer) StopCallCount() int {
+	fake.stopMutex.RLock()
+	defer fake.stopMutex.RUnlock()
+	return len(fake.stopArgsForCall)
+}
+
+func (fake *InterfaceRebalancer) StopCalls(stub func()) {
+	fake.stopMutex.Lock()
+	defer fake.stopMutex.Unlock()
+	fake.StopStub = stub
+}
+
+func (fake *InterfaceRebalancer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.setThrottleMutex.RLock()
+	defer fake.setThrottleMutex.RUnlock()
+	fake.startMutex.RLock()
+	defer fake.startMutex.RUnlock()
+	fake.statusMutex.RLock()
+	defer fake.statusMutex.RUnlock()
+	fake.stopMutex.RLock()
+	defer fake.stopMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *InterfaceRebalancer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ objectStorage.Rebalancer = new(InterfaceRebalancer)
@@ -0,0 +1,156 @@
+package objectStorage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+// repairTimeout bounds a single read repair
+const repairTimeout = 5 * time.Minute
+
+// fallbackConcurrency bounds the number of nodes probed at once for an object missing from its replicas
+const fallbackConcurrency = 8
+
+// fallbackCandidates returns the nodes that may still hold an object missing from its replicas: its
+// owners under the previous topology, and every other node. The replicas themselves are left out,
+// they were already asked and too many of them lack the object for the read quorum to be met.
+func (s *minioStorageService) fallbackCandidates(objectID string, replicas []replica) ([]replica, []replica) {
+	s.clientsMutex.RLock()
+	defer s.clientsMutex.RUnlock()
+
+	excluded := make(map[string]bool, len(s.nodes))
+	for _, r := range replicas {
+		excluded[r.node.ID] = true
+	}
+
+	var previous, others []replica
+	if s.previousPlacement != nil {
+		for _, nodeID := range s.previousPlacement.Locate(objectID, s.previousReplicationFactor) {
+			if node, ok := s.nodeByID(nodeID); ok && !excluded[nodeID] {
+				excluded[nodeID] = true
+				previous = append(previous, s.replicaOf(node))
+			}
+		}
+	}
+	for _, node := range s.nodes {
+		if !excluded[node.ID] {
+			others = append(others, s.replicaOf(node))
+		}
+	}
+
+	return previous, others
+}
+
+// forEachCandidate runs operation for every candidate with a client, fallbackConcurrency at a time
+func forEachCandidate(candidates []replica, operation func(candidate replica)) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, fallbackConcurrency)
+	for _, candidate := range candidates {
+		if candidate.client == nil {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(candidate replica) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			operation(candidate)
+		}(candidate)
+	}
+	wg.Wait()
+}
+
+// findMisplaced looks for an object on the nodes outside its replicas, returning the node holding
+// the newest version. The owners under the previous topology are asked first, every other node only
+// when none of them has the object. It returns ErrNotFound when no node has the object.
+func (s *minioStorageService) findMisplaced(ctx context.Context, objectID string, replicas []replica) (replica, minio.ObjectInfo, error) {
+	previous, others := s.fallbackCandidates(objectID, replicas)
+	found, info, ok := s.probeCandidates(ctx, objectID, previous)
+	if !ok {
+		found, info, ok = s.probeCandidates(ctx, objectID, others)
+	}
+	if !ok {
+		return replica{}, minio.ObjectInfo{}, ErrNotFound
+	}
+
+	s.logger.Info("Object found outside its replicas",
+		zap.String("object_id", objectID), zap.String("node_name", found.node.Name), zap.Strings("replicas", replicaNames(replicas)))
+	return found, info, nil
+}
+
+// probeCandidates asks the candidates for an object at once and returns the one holding the newest version
+func (s *minioStorageService) probeCandidates(ctx context.Context, objectID string, candidates []replica) (replica, minio.ObjectInfo, bool) {
+	var mutex sync.Mutex
+	var found replica
+	var newest minio.ObjectInfo
+	forEachCandidate(candidates, func(candidate replica) {
+		var info minio.ObjectInfo
+		err := candidate.call(func() (err error) {
+			info, err = candidate.client.StatObject(ctx, bucketName, objectID, minio.StatObjectOptions{})
+			return err
+		})
+		if err != nil {
+			if minio.ToErrorResponse(err).Code != "NoSuchKey" {
+				s.logger.Warn("Failed to probe node for misplaced object",
+					zap.String("object_id", objectID), zap.String("node_name", candidate.node.Name), zap.Error(err))
+			}
+			return
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if found.client == nil || info.LastModified.After(newest.LastModified) {
+			found, newest = candidate, info
+		}
+	})
+
+	return found, newest, found.client != nil
+}
+
+// repairOnRead copies an object found outside its replicas to every replica in the background.
+// The copies never replace an existing version, and the misplaced copy is left for the rebalancer.
+func (s *minioStorageService) repairOnRead(objectID string, source replica, etag string, replicas []replica) {
+	if !s.config.ReadRepair {
+		return
+	}
+	// Concurrent reads of the same object only trigger one repair
+	if _, running := s.repairs.LoadOrStore(objectID, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer s.repairs.Delete(objectID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), repairTimeout)
+		defer cancel()
+
+		for _, target := range replicas {
+			if target.client == nil || target.node.ID == source.node.ID {
+				continue
+			}
+			err := copyBetweenNodes(ctx, source, target, objectID, etag, "")
+			if err != nil && !errors.Is(err, errTargetChanged) {
+				s.logger.Warn("Failed to repair object on read",
+					zap.String("object_id", objectID), zap.String("node_name", target.node.Name), zap.Error(err))
+				continue
+			}
+			s.logger.Info("Repaired object on read", zap.String("object_id", objectID), zap.String("node_name", target.node.Name))
+		}
+	}()
+}
+
+// deleteMisplaced removes an object from the nodes outside its replicas on a best effort basis
+func (s *minioStorageService) deleteMisplaced(ctx context.Context, objectID string, replicas []replica) {
+	previous, others := s.fallbackCandidates(objectID, replicas)
+	forEachCandidate(append(previous, others...), func(candidate replica) {
+		if err := candidate.client.RemoveObject(ctx, bucketName, objectID, minio.RemoveObjectOptions{}); err != nil {
+			s.logger.Warn("Failed to delete misplaced copy of object",
+				zap.String("object_id", objectID), zap.String("node_name", candidate.node.Name), zap.Error(err))
+		}
+	})
+}
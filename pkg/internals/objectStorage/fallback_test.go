@@ -0,0 +1,205 @@
+package objectStorage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackCandidates(t *testing.T) {
+	testCases := []struct {
+		name         string
+		previous     int
+		withPrevious bool
+	}{
+		{name: "Without Previous Placement"},
+		{name: "Previous Placement First", previous: 2, withPrevious: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes := newTestNodes(5)
+			service := newTestService(nodes, DefaultConfig())
+			if tc.withPrevious {
+				service.previousPlacement = newPlacement(nodes[:3], DefaultConfig())
+				service.previousReplicationFactor = tc.previous
+			}
+
+			for i := 0; i < 50; i++ {
+				objectID := fmt.Sprintf("object%d", i)
+				replicas, err := service.getReplicasForID(objectID)
+				assert.NoError(t, err)
+
+				previous, others := service.fallbackCandidates(objectID, replicas)
+				// Every node outside the replicas is probed exactly once
+				assert.Len(t, append(previous, others...), len(nodes)-len(replicas))
+				seen := map[string]bool{replicas[0].node.ID: true}
+				for _, c := range append(previous, others...) {
+					assert.False(t, seen[c.node.ID])
+					seen[c.node.ID] = true
+				}
+
+				var expected []string
+				if tc.withPrevious {
+					for _, id := range service.previousPlacement.Locate(objectID, tc.previous) {
+						if id != replicas[0].node.ID {
+							expected = append(expected, id)
+						}
+					}
+				}
+				assert.Equal(t, expected, replicaIDs(previous))
+			}
+		})
+	}
+}
+
+func replicaIDs(replicas []replica) []string {
+	var ids []string
+	for _, r := range replicas {
+		ids = append(ids, r.node.ID)
+	}
+	return ids
+}
+
+// newTestMovedCluster builds a cluster of four fake nodes that grew from three, and returns an object
+// whose owner changed together with its current replica, its previous owner and the remaining nodes
+func newTestMovedCluster(t *testing.T, config Config) (*minioStorageService, []*fakeS3, string, replica, replica, []replica) {
+	service, fakes, _ := newTestCluster(t, 4, config)
+	service.previousPlacement = newPlacement(service.nodes[:3], config)
+	service.previousReplicationFactor = 1
+
+	for i := 0; ; i++ {
+		objectID := fmt.Sprintf("object%d", i)
+		replicas, err := service.getReplicasForID(objectID)
+		require.NoError(t, err)
+		previous, others := service.fallbackCandidates(objectID, replicas)
+		if len(previous) == 1 {
+			return service, fakes, objectID, replicas[0], previous[0], others
+		}
+	}
+}
+
+// ageObject makes the copy of an object held by a fake node look older
+func ageObject(fake *fakeS3, objectID string) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	object := fake.objects[objectID]
+	object.modified = object.modified.Add(-time.Hour)
+	fake.objects[objectID] = object
+}
+
+// fakeOf returns the fake server behind a replica of a cluster built by newTestCluster
+func fakeOf(service *minioStorageService, fakes []*fakeS3, r replica) *fakeS3 {
+	for i, node := range service.nodes {
+		if node.ID == r.node.ID {
+			return fakes[i]
+		}
+	}
+	return nil
+}
+
+func TestFindMisplaced(t *testing.T) {
+	testCases := []struct {
+		name     string
+		previous string
+		others   []string
+		// agedOther makes the first other node hold an older version
+		agedOther bool
+		expected  string
+	}{
+		{name: "Not Found"},
+		{name: "On Previous Owner", previous: "v1", expected: "previous"},
+		{name: "On Another Node", others: []string{"v1", ""}, expected: "other-0"},
+		// The other nodes are not asked once the previous owner has the object
+		{name: "Previous Owner First", previous: "v1", others: []string{"v2", ""}, expected: "previous"},
+		{name: "Newest Copy", others: []string{"v1", "v2"}, agedOther: true, expected: "other-1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, fakes, objectID, current, previous, others := newTestMovedCluster(t, DefaultConfig())
+			if tc.previous != "" {
+				putOnReplica(t, previous, objectID, tc.previous)
+			}
+			for i, content := range tc.others {
+				if content != "" {
+					putOnReplica(t, others[i], objectID, content)
+				}
+			}
+			if tc.agedOther {
+				ageObject(fakeOf(service, fakes, others[0]), objectID)
+			}
+			names := map[string]string{previous.node.ID: "previous", others[0].node.ID: "other-0", others[1].node.ID: "other-1"}
+
+			found, _, err := service.findMisplaced(context.Background(), objectID, []replica{current})
+
+			if tc.expected == "" {
+				assert.ErrorIs(t, err, ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, names[found.node.ID])
+		})
+	}
+}
+
+func TestRepairOnRead(t *testing.T) {
+	testCases := []struct {
+		name       string
+		readRepair bool
+		existing   string
+		expected   string
+	}{
+		{name: "Copied To Replica", readRepair: true, expected: "content"},
+		{name: "Disabled", expected: ""},
+		{name: "Existing Version Kept", readRepair: true, existing: "newer", expected: "newer"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.ReadRepair = tc.readRepair
+			service, _, objectID, current, previous, _ := newTestMovedCluster(t, config)
+			putOnReplica(t, previous, objectID, "content")
+			if tc.existing != "" {
+				putOnReplica(t, current, objectID, tc.existing)
+			}
+			source, info, err := service.findMisplaced(context.Background(), objectID, []replica{current})
+			require.NoError(t, err)
+
+			service.repairOnRead(objectID, source, info.ETag, []replica{current})
+
+			// The repair runs in the background until it is removed from the running repairs
+			assert.Eventually(t, func() bool {
+				_, running := service.repairs.Load(objectID)
+				return !running
+			}, time.Second, 10*time.Millisecond)
+			if tc.expected == "" {
+				assert.False(t, replicaHolds(t, current, objectID, "content"))
+			} else {
+				assert.True(t, replicaHolds(t, current, objectID, tc.expected))
+			}
+			// The misplaced copy is left for the rebalancer
+			assert.True(t, replicaHolds(t, previous, objectID, "content"))
+		})
+	}
+}
+
+func TestDeleteMisplaced(t *testing.T) {
+	service, _, objectID, current, previous, others := newTestMovedCluster(t, DefaultConfig())
+	for _, r := range append([]replica{current, previous}, others...) {
+		putOnReplica(t, r, objectID, "content")
+	}
+
+	service.deleteMisplaced(context.Background(), objectID, []replica{current})
+
+	// Only the replica keeps its copy, deleting that one is left to the caller
+	assert.True(t, replicaHolds(t, current, objectID, "content"))
+	for _, r := range append([]replica{previous}, others...) {
+		assert.False(t, replicaHolds(t, r, objectID, "content"), r.node.Name)
+	}
+}
@@ -0,0 +1,368 @@
+package objectStorage
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	utils "github.com/singhmeghna79/homework-object-storage/pkg"
+	"go.uber.org/zap"
+)
+
+const (
+	// fsObjectsDir holds the objects, sharded into two levels of subdirectories
+	fsObjectsDir = "objects"
+	// fsUploadsDir holds uploads until they are renamed into place
+	fsUploadsDir = "uploads"
+	// fsStaleUpload is the age after which an upload left behind by a crash is removed
+	fsStaleUpload = time.Hour
+	// fsLockStripes is the number of locks serializing the writes to an object
+	fsLockStripes = 64
+	// fsTrailerSize is the size of the length field ending every object file
+	fsTrailerSize = 8
+)
+
+// filesystemStorageService stores objects as files below a local directory.
+//
+// Every object is a single file holding its content followed by its metadata as JSON and the
+// length of that JSON, so a rename replaces both at once and ranges are read at their offsets.
+// Uploads are written and fsynced in a separate directory before they are renamed into place.
+type filesystemStorageService struct {
+	root   string
+	locks  [fsLockStripes]sync.Mutex
+	logger *zap.Logger
+}
+
+// NewFilesystemStorageService creates the storage directories below root and removes uploads
+// left behind by a crash
+func NewFilesystemStorageService(root string, logger *zap.Logger) (*filesystemStorageService, error) {
+	if root == "" {
+		return nil, fmt.Errorf("filesystem storage needs a root directory")
+	}
+
+	service := &filesystemStorageService{root: root, logger: logger}
+	for _, dir := range []string{service.objectsDir(), service.uploadsDir()} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+	}
+
+	entries, err := os.ReadDir(service.uploadsDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploads directory: %w", err)
+	}
+	for _, entry := range entries {
+		// Recent uploads may belong to another gateway sharing the directory
+		if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > fsStaleUpload {
+			os.Remove(filepath.Join(service.uploadsDir(), entry.Name()))
+		}
+	}
+
+	logger.Info("Storing objects on the local filesystem", zap.String("root", root))
+	return service, nil
+}
+
+func (s *filesystemStorageService) objectsDir() string {
+	return filepath.Join(s.root, fsObjectsDir)
+}
+
+func (s *filesystemStorageService) uploadsDir() string {
+	return filepath.Join(s.root, fsUploadsDir)
+}
+
+// objectPath returns the file of an object. The directories are chosen by a hash of the ID,
+// so objects spread evenly whatever their names.
+func (s *filesystemStorageService) objectPath(objectID string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(objectID))
+	shard := fmt.Sprintf("%08x", hash.Sum32())
+	return filepath.Join(s.objectsDir(), shard[:2], shard[2:4], objectID)
+}
+
+// lock returns the lock serializing the writes to an object
+func (s *filesystemStorageService) lock(objectID string) *sync.Mutex {
+	hash := fnv.New32a()
+	hash.Write([]byte(objectID))
+	return &s.locks[hash.Sum32()%fsLockStripes]
+}
+
+// PutObject writes the object to a temporary file and renames it into place once it is on disk
+func (s *filesystemStorageService) PutObject(ctx *gin.Context, objectID string, data io.Reader, size int64, opts PutOptions) error {
+	logger := utils.GetLogger(ctx)
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
+	}
+
+	upload, err := os.CreateTemp(s.uploadsDir(), objectID+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create upload: %w", err)
+	}
+	// Removing the upload fails harmlessly once it has been renamed
+	defer os.Remove(upload.Name())
+	defer upload.Close()
+
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(upload, hash), io.LimitReader(data, size+1))
+	if err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if written != size {
+		return fmt.Errorf("failed to write object: received %d bytes, expected %d", written, size)
+	}
+
+	info := ObjectInfo{
+		ID:                 objectID,
+		Size:               size,
+		ETag:               hex.EncodeToString(hash.Sum(nil)),
+		LastModified:       time.Now().UTC(),
+		ContentType:        opts.ContentType,
+		ContentDisposition: opts.ContentDisposition,
+		CacheControl:       opts.CacheControl,
+		UserMetadata:       opts.UserMetadata,
+	}
+	if err := writeTrailer(upload, info); err != nil {
+		return err
+	}
+	if err := upload.Sync(); err != nil {
+		return fmt.Errorf("failed to sync object: %w", err)
+	}
+	if err := upload.Close(); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+
+	path := s.objectPath(objectID)
+	mutex := s.lock(objectID)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// The conditions are checked under the lock, so racing writers of this process cannot overwrite each other
+	if opts.IfMatch != "" || opts.IfNoneMatch != "" {
+		current, err := readObjectInfo(path)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			err = opts.checkConditions(nil)
+		case err == nil:
+			err = opts.checkConditions(&current)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+	if err := os.Rename(upload.Name(), path); err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	logger.Info("Stored object on the filesystem", zap.String("object_id", objectID), zap.Int64("size", size))
+	return nil
+}
+
+// GetObject opens the object. With a range only those bytes are read from the file.
+func (s *filesystemStorageService) GetObject(ctx *gin.Context, objectID string, opts GetOptions) (io.ReadCloser, ObjectInfo, error) {
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	file, err := os.Open(s.objectPath(objectID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ObjectInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, ObjectInfo{}, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	info, err := readTrailer(file)
+	if err != nil {
+		file.Close()
+		return nil, ObjectInfo{}, err
+	}
+
+	start, length := int64(0), info.Size
+	if opts.Range != nil {
+		if opts.Range.Start < 0 || opts.Range.Start > opts.Range.End || opts.Range.Start >= info.Size {
+			file.Close()
+			return nil, ObjectInfo{}, NewError(ErrInvalidRange, fmt.Sprintf("invalid range: %d-%d of %d bytes", opts.Range.Start, opts.Range.End, info.Size), nil)
+		}
+		start, length = opts.Range.Start, min(opts.Range.Length(), info.Size-opts.Range.Start)
+		info.Size = length
+	}
+
+	return fileSection{Reader: io.NewSectionReader(file, start, length), Closer: file}, info, nil
+}
+
+// fileSection reads part of an open file and closes the file when done
+type fileSection struct {
+	io.Reader
+	io.Closer
+}
+
+// StatObject returns the metadata stored with the object
+func (s *filesystemStorageService) StatObject(ctx *gin.Context, objectID string) (ObjectInfo, error) {
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return ObjectInfo{}, err
+	}
+	return readObjectInfo(s.objectPath(objectID))
+}
+
+// DeleteObject removes the object file. Deleting an object that does not exist is not an error.
+func (s *filesystemStorageService) DeleteObject(ctx *gin.Context, objectID string) error {
+	logger := utils.GetLogger(ctx)
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
+	}
+
+	path := s.objectPath(objectID)
+	mutex := s.lock(objectID)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	logger.Info("Deleted object from the filesystem", zap.String("object_id", objectID))
+	return nil
+}
+
+// ListObjects walks the object directories. The files are spread by hash, so every listing
+// reads all names and sorts them.
+func (s *filesystemStorageService) ListObjects(ctx *gin.Context, opts ListOptions) (*ListResult, error) {
+	limit, startAfter, err := normalizeListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	err = filepath.WalkDir(s.objectsDir(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, opts.Prefix) && name > startAfter {
+			ids = append(ids, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	sort.Strings(ids)
+
+	result := &ListResult{Objects: []ObjectInfo{}}
+	for _, id := range ids {
+		if len(result.Objects) == limit {
+			result.IsTruncated = true
+			result.NextCursor = encodeListCursor(result.Objects[limit-1].ID)
+			break
+		}
+
+		info, err := readObjectInfo(s.objectPath(id))
+		if errors.Is(err, ErrNotFound) {
+			// Deleted while the listing was running
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result.Objects = append(result.Objects, info.listed())
+	}
+
+	return result, nil
+}
+
+// readObjectInfo reads the metadata stored at the end of an object file
+func readObjectInfo(path string) (ObjectInfo, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ObjectInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to open object: %w", err)
+	}
+	defer file.Close()
+
+	return readTrailer(file)
+}
+
+// writeTrailer appends the metadata and its length after the content of an object
+func writeTrailer(w io.Writer, info ObjectInfo) error {
+	metadata, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to encode object metadata: %w", err)
+	}
+	metadata = binary.BigEndian.AppendUint64(metadata, uint64(len(metadata)))
+	if _, err := w.Write(metadata); err != nil {
+		return fmt.Errorf("failed to write object metadata: %w", err)
+	}
+	return nil
+}
+
+// readTrailer reads the metadata written by writeTrailer from the end of an object file
+func readTrailer(file *os.File) (ObjectInfo, error) {
+	stat, err := file.Stat()
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	var length [fsTrailerSize]byte
+	if _, err := file.ReadAt(length[:], stat.Size()-fsTrailerSize); err != nil {
+		return ObjectInfo{}, fmt.Errorf("corrupt object file %s: %w", file.Name(), err)
+	}
+	metadataSize := int64(binary.BigEndian.Uint64(length[:]))
+	if metadataSize < 0 || metadataSize > stat.Size()-fsTrailerSize {
+		return ObjectInfo{}, fmt.Errorf("corrupt object file %s: metadata size %d", file.Name(), metadataSize)
+	}
+
+	metadata := make([]byte, metadataSize)
+	if _, err := file.ReadAt(metadata, stat.Size()-fsTrailerSize-metadataSize); err != nil {
+		return ObjectInfo{}, fmt.Errorf("corrupt object file %s: %w", file.Name(), err)
+	}
+	var info ObjectInfo
+	if err := json.Unmarshal(metadata, &info); err != nil {
+		return ObjectInfo{}, fmt.Errorf("corrupt object file %s: %w", file.Name(), err)
+	}
+	return info, nil
+}
+
+// syncDir flushes a directory, making the renames and removals in it durable
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+	defer dir.Close()
+	if err := dir.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package objectStorage
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestFilesystemService(t *testing.T) (*filesystemStorageService, *gin.Context) {
+	service, err := NewFilesystemStorageService(t.TempDir(), zap.NewNop())
+	require.NoError(t, err)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	return service, ctx
+}
+
+func putString(ctx *gin.Context, storage ObjectStorage, objectID string, content string, opts PutOptions) error {
+	return storage.PutObject(ctx, objectID, strings.NewReader(content), int64(len(content)), opts)
+}
+
+func TestFilesystemRoundTrip(t *testing.T) {
+	service, ctx := newTestFilesystemService(t)
+	content := "This is a test object"
+
+	err := putString(ctx, service, "test123", content, PutOptions{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"Owner": "sync"},
+	})
+	require.NoError(t, err)
+
+	// The file is sharded below the objects directory and no upload is left behind
+	path := service.objectPath("test123")
+	assert.FileExists(t, path)
+	rel, _ := filepath.Rel(service.objectsDir(), path)
+	assert.Len(t, strings.Split(rel, string(filepath.Separator)), 3)
+	uploads, _ := os.ReadDir(service.uploadsDir())
+	assert.Empty(t, uploads)
+
+	obj, info, err := service.GetObject(ctx, "test123", GetOptions{})
+	require.NoError(t, err)
+	data, _ := io.ReadAll(obj)
+	obj.Close()
+	sum := md5.Sum([]byte(content))
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.Equal(t, hex.EncodeToString(sum[:]), info.ETag)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, map[string]string{"Owner": "sync"}, info.UserMetadata)
+
+	stat, err := service.StatObject(ctx, "test123")
+	require.NoError(t, err)
+	assert.Equal(t, info, stat)
+
+	// Ranges are read at their offsets
+	obj, info, err = service.GetObject(ctx, "test123", GetOptions{Range: &ByteRange{Start: 10, End: 13}})
+	require.NoError(t, err)
+	data, _ = io.ReadAll(obj)
+	obj.Close()
+	assert.Equal(t, "test", string(data))
+	assert.Equal(t, int64(4), info.Size)
+	_, _, err = service.GetObject(ctx, "test123", GetOptions{Range: &ByteRange{Start: 21, End: 30}})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	// Deletes are idempotent
+	require.NoError(t, service.DeleteObject(ctx, "test123"))
+	require.NoError(t, service.DeleteObject(ctx, "test123"))
+	_, _, err = service.GetObject(ctx, "test123", GetOptions{})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.StatObject(ctx, "test123")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFilesystemPutErrors(t *testing.T) {
+	service, ctx := newTestFilesystemService(t)
+	require.NoError(t, putString(ctx, service, "existing", "v1", PutOptions{}))
+	current, _ := service.StatObject(ctx, "existing")
+
+	testCases := []struct {
+		name          string
+		objectID      string
+		content       string
+		size          int64
+		opts          PutOptions
+		expectedError error
+	}{
+		{name: "Invalid ID", objectID: "not-valid", content: "data", size: 4, expectedError: ErrInvalidID},
+		{name: "Short Body", objectID: "short", content: "data", size: 10},
+		{name: "Create Only", objectID: "existing", content: "v2", size: 2, opts: PutOptions{IfNoneMatch: "*"}, expectedError: ErrPreconditionFailed},
+		{name: "Stale ETag", objectID: "existing", content: "v2", size: 2, opts: PutOptions{IfMatch: "0123"}, expectedError: ErrPreconditionFailed},
+		{name: "Missing Object", objectID: "missing", content: "v2", size: 2, opts: PutOptions{IfMatch: "*"}, expectedError: ErrPreconditionFailed},
+		{name: "Current ETag", objectID: "existing", content: "v2", size: 2, opts: PutOptions{IfMatch: current.ETag}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := service.PutObject(ctx, tc.objectID, strings.NewReader(tc.content), tc.size, tc.opts)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else if int64(len(tc.content)) != tc.size {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// Failed uploads do not leave files behind
+	uploads, _ := os.ReadDir(service.uploadsDir())
+	assert.Empty(t, uploads)
+	_, err := service.StatObject(ctx, "short")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFilesystemCorruptTrailer(t *testing.T) {
+	service, ctx := newTestFilesystemService(t)
+	require.NoError(t, putString(ctx, service, "corrupt", "data", PutOptions{}))
+
+	testCases := []struct {
+		name         string
+		metadataSize uint64
+	}{
+		{name: "Metadata Larger Than File", metadataSize: 1 << 20},
+		{name: "Negative Metadata Size", metadataSize: 1 << 63},
+		{name: "Invalid Metadata", metadataSize: 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := binary.BigEndian.AppendUint64([]byte("data"), tc.metadataSize)
+			require.NoError(t, os.WriteFile(service.objectPath("corrupt"), content, 0o600))
+
+			_, err := service.StatObject(ctx, "corrupt")
+			assert.ErrorContains(t, err, "corrupt object file")
+		})
+	}
+}
+
+func TestFilesystemCreateOnlyRace(t *testing.T) {
+	service, ctx := newTestFilesystemService(t)
+
+	var wg sync.WaitGroup
+	results := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results <- putString(ctx, service, "contended", fmt.Sprintf("writer%d", i), PutOptions{IfNoneMatch: "*"})
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	// Exactly one create-only write wins
+	var succeeded int
+	for err := range results {
+		if err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, ErrPreconditionFailed)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestFilesystemListObjects(t *testing.T) {
+	service, ctx := newTestFilesystemService(t)
+	for _, id := range []string{"b2", "a1", "c3", "a2", "a3"} {
+		require.NoError(t, putString(ctx, service, id, "content", PutOptions{ContentType: "text/plain"}))
+	}
+
+	first, err := service.ListObjects(ctx, ListOptions{Prefix: "a", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2"}, objectIDs(first.Objects))
+	assert.True(t, first.IsTruncated)
+	assert.Empty(t, first.Objects[0].ContentType)
+
+	second, err := service.ListObjects(ctx, ListOptions{Prefix: "a", Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a3"}, objectIDs(second.Objects))
+	assert.False(t, second.IsTruncated)
+	assert.Empty(t, second.NextCursor)
+
+	all, err := service.ListObjects(ctx, ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2", "a3", "b2", "c3"}, objectIDs(all.Objects))
+
+	_, err = service.ListObjects(ctx, ListOptions{Cursor: "!"})
+	assert.ErrorIs(t, err, ErrInvalidListOptions)
+}
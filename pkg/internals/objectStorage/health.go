@@ -0,0 +1,200 @@
+package objectStorage
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"go.uber.org/zap"
+)
+
+// livenessPath is the Minio endpoint reporting whether a node is up
+const livenessPath = "/minio/health/live"
+
+//go:generate counterfeiter -o fakes/InterfaceHealthChecker.go --fake-name InterfaceHealthChecker . HealthChecker
+type HealthChecker interface {
+	// NodeHealth returns the health of every node of the current topology
+	NodeHealth() []NodeHealth
+}
+
+// HealthCheckerProvider is implemented by backends that check the health of their nodes
+type HealthCheckerProvider interface {
+	HealthChecker() HealthChecker
+}
+
+// NodeHealth reports the result of the recent probes of a node
+type NodeHealth struct {
+	ID                   string     `json:"id"`
+	Name                 string     `json:"name"`
+	Endpoint             string     `json:"endpoint"`
+	Zone                 string     `json:"zone,omitempty"`
+	Healthy              bool       `json:"healthy"`
+	ConsecutiveFailures  int        `json:"consecutive_failures"`
+	ConsecutiveSuccesses int        `json:"consecutive_successes"`
+	LastCheck            *time.Time `json:"last_check,omitempty"`
+	LastError            string     `json:"last_error,omitempty"`
+}
+
+// healthChecker probes every node periodically. A node is ejected after UnhealthyThreshold
+// consecutive failed probes and re-admitted after HealthyThreshold consecutive successful ones.
+// Nodes start out healthy so a new node serves requests before its first probe.
+type healthChecker struct {
+	service *minioStorageService
+	probe   func(ctx context.Context, node docker.MinioNode) error
+	config  Config
+	logger  *zap.Logger
+
+	mutex  sync.RWMutex
+	states map[string]*NodeHealth
+}
+
+func newHealthChecker(service *minioStorageService, config Config, logger *zap.Logger) *healthChecker {
+	client := &http.Client{Timeout: config.HealthCheckTimeout}
+	return &healthChecker{
+		service: service,
+		probe: func(ctx context.Context, node docker.MinioNode) error {
+			return probeNode(ctx, client, node)
+		},
+		config: config,
+		logger: logger,
+		states: make(map[string]*NodeHealth),
+	}
+}
+
+// run probes the nodes every interval until the context is cancelled
+func (h *healthChecker) run(ctx context.Context) {
+	ticker := time.NewTicker(h.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		h.checkNodes(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkNodes probes every node of the current topology once
+func (h *healthChecker) checkNodes(ctx context.Context) {
+	nodes := h.service.currentNodes()
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node docker.MinioNode) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, h.config.HealthCheckTimeout)
+			defer cancel()
+			h.record(node, h.probe(probeCtx, node))
+		}(node)
+	}
+	wg.Wait()
+
+	// Forget nodes that left the cluster
+	current := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		current[node.ID] = true
+	}
+	h.mutex.Lock()
+	for id := range h.states {
+		if !current[id] {
+			delete(h.states, id)
+		}
+	}
+	h.mutex.Unlock()
+}
+
+// record updates the state of a node with the result of a probe
+func (h *healthChecker) record(node docker.MinioNode, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	state, ok := h.states[node.ID]
+	if !ok {
+		state = &NodeHealth{Healthy: true}
+		h.states[node.ID] = state
+	}
+	now := time.Now()
+	state.LastCheck = &now
+
+	if err == nil {
+		state.ConsecutiveSuccesses++
+		state.ConsecutiveFailures = 0
+		state.LastError = ""
+		if !state.Healthy && state.ConsecutiveSuccesses >= h.config.HealthyThreshold {
+			state.Healthy = true
+			h.logger.Info("Node re-admitted after successful health checks",
+				zap.String("node_name", node.Name), zap.Int("successes", state.ConsecutiveSuccesses))
+		}
+		return
+	}
+
+	state.ConsecutiveFailures++
+	state.ConsecutiveSuccesses = 0
+	state.LastError = err.Error()
+	if state.Healthy && state.ConsecutiveFailures >= h.config.UnhealthyThreshold {
+		state.Healthy = false
+		h.logger.Warn("Node ejected after failed health checks",
+			zap.String("node_name", node.Name), zap.Int("failures", state.ConsecutiveFailures), zap.Error(err))
+	}
+}
+
+// isHealthy reports whether a node passed its recent probes, nodes that were not probed yet are healthy
+func (h *healthChecker) isHealthy(nodeID string) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	state, ok := h.states[nodeID]
+	return !ok || state.Healthy
+}
+
+func (h *healthChecker) NodeHealth() []NodeHealth {
+	nodes := h.service.currentNodes()
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	health := make([]NodeHealth, 0, len(nodes))
+	for _, node := range nodes {
+		entry := NodeHealth{Healthy: true}
+		if state, ok := h.states[node.ID]; ok {
+			entry = *state
+		}
+		entry.ID = node.ID
+		entry.Name = node.Name
+		entry.Endpoint = net.JoinHostPort(node.IPAddress, node.Port)
+		entry.Zone = node.Zone
+		health = append(health, entry)
+	}
+	return health
+}
+
+// probeNode checks that a node accepts connections and that Minio reports itself as live
+func probeNode(ctx context.Context, client *http.Client, node docker.MinioNode) error {
+	if !docker.ValidateNodeConnection(ctx, node) {
+		return fmt.Errorf("node %s does not accept connections", node.Name)
+	}
+
+	url := fmt.Sprintf("http://%s%s", net.JoinHostPort(node.IPAddress, node.Port), livenessPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("liveness check failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("liveness check returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package objectStorage
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheckerThresholds(t *testing.T) {
+	nodes := newTestNodes(3)
+	config := DefaultConfig()
+	config.UnhealthyThreshold, config.HealthyThreshold = 3, 2
+	service := newTestService(nodes, config)
+
+	failing := map[string]bool{}
+	service.health.probe = func(ctx context.Context, node docker.MinioNode) error {
+		if failing[node.ID] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	// Nodes stay healthy until the threshold is reached
+	failing[nodes[0].ID] = true
+	for i := 0; i < 2; i++ {
+		service.health.checkNodes(context.Background())
+		assert.True(t, service.health.isHealthy(nodes[0].ID))
+	}
+	service.health.checkNodes(context.Background())
+	assert.False(t, service.health.isHealthy(nodes[0].ID))
+
+	health := service.health.NodeHealth()
+	assert.Len(t, health, 3)
+	assert.Equal(t, nodes[0].Name, health[0].Name)
+	assert.False(t, health[0].Healthy)
+	assert.Equal(t, 3, health[0].ConsecutiveFailures)
+	assert.Equal(t, "connection refused", health[0].LastError)
+	assert.True(t, health[1].Healthy)
+
+	// Re-admission needs consecutive successes
+	failing[nodes[0].ID] = false
+	service.health.checkNodes(context.Background())
+	assert.False(t, service.health.isHealthy(nodes[0].ID))
+	service.health.checkNodes(context.Background())
+	assert.True(t, service.health.isHealthy(nodes[0].ID))
+
+	// Nodes that left are forgotten
+	failing[nodes[2].ID] = true
+	for i := 0; i < 3; i++ {
+		service.health.checkNodes(context.Background())
+	}
+	service.UpdateNodes(nodes[:2])
+	service.health.checkNodes(context.Background())
+	assert.NotContains(t, service.health.states, nodes[2].ID)
+}
+
+func TestReadReplicas(t *testing.T) {
+	nodes := newTestNodes(3)
+	service := newTestService(nodes, DefaultConfig())
+	replicas := []replica{{node: nodes[0]}, {node: nodes[1]}, {node: nodes[2]}}
+	for i := 0; i < service.config.UnhealthyThreshold; i++ {
+		service.health.record(nodes[0], errors.New("timeout"))
+	}
+
+	// Unhealthy replicas are skipped while the quorum can be reached without them
+	assert.Equal(t, []replica{replicas[1], replicas[2]}, service.readReplicas(replicas, 2))
+	assert.Equal(t, []replica{replicas[1], replicas[2], replicas[0]}, service.readReplicas(replicas, 3))
+}
+
+func TestProbeNode(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, livenessPath, r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	node := docker.MinioNode{Name: "node-1", IPAddress: host, Port: port}
+	assert.NoError(t, probeNode(context.Background(), server.Client(), node))
+
+	// The connection check gives up with the probe
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.EqualError(t, probeNode(ctx, server.Client(), node), "node node-1 does not accept connections")
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, probeNode(context.Background(), server.Client(), node), "liveness check returned 503 Service Unavailable")
+
+	server.Close()
+	assert.EqualError(t, probeNode(context.Background(), server.Client(), node), "node node-1 does not accept connections")
+}
@@ -0,0 +1,204 @@
+package objectStorage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/minio/minio-go/v7"
+	utils "github.com/singhmeghna79/homework-object-storage/pkg"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// ErrInvalidListOptions is returned when the prefix, limit or cursor of a listing is rejected
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// nodeListing is the sorted object stream returned by a single node
+type nodeListing struct {
+	node    string
+	objects <-chan minio.ObjectInfo
+	head    minio.ObjectInfo
+	// err is the error the listing failed with
+	err error
+}
+
+// ListObjects lists objects across all nodes, merged into a single stream sorted by ID.
+// Nodes that fail to answer are reported in the result instead of failing the request.
+func (s *minioStorageService) ListObjects(ctx *gin.Context, opts ListOptions) (*ListResult, error) {
+	logger := utils.GetLogger(ctx)
+
+	limit, startAfter, err := normalizeListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Snapshot the node set so the listing is not affected by concurrent changes
+	s.clientsMutex.RLock()
+	nodes := make([]replica, 0, len(s.nodes))
+	for _, node := range s.nodes {
+		nodes = append(nodes, s.replicaOf(node))
+	}
+	s.clientsMutex.RUnlock()
+
+	if len(nodes) == 0 {
+		return nil, NewError(ErrUnavailable, "no storage nodes available", nil)
+	}
+
+	// Cancelling the context stops the remaining listings once the page is full
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var listings []*nodeListing
+	var breakers []*circuitBreaker
+	var failedNodes []string
+	for _, r := range nodes {
+		// Nodes behind an open circuit breaker are reported as failed right away
+		if r.client == nil || r.breaker.allow() != nil {
+			failedNodes = append(failedNodes, r.node.Name)
+			continue
+		}
+		breakers = append(breakers, r.breaker)
+		listings = append(listings, &nodeListing{
+			node: r.node.Name,
+			objects: r.client.ListObjects(listCtx, bucketName, minio.ListObjectsOptions{
+				Prefix:     opts.Prefix,
+				StartAfter: startAfter,
+				Recursive:  true,
+			}),
+		})
+	}
+
+	objects, truncated, failed := mergeListings(listings, limit)
+	for i, l := range listings {
+		breakers[i].record(l.err)
+	}
+	failedNodes = append(failedNodes, failed...)
+	sort.Strings(failedNodes)
+
+	if len(failedNodes) == len(nodes) {
+		return nil, NewError(ErrUnavailable, "failed to list objects: no storage nodes available", nil)
+	}
+	if len(failedNodes) > 0 {
+		logger.Warn("Listing is missing results from nodes", zap.Strings("failed_nodes", failedNodes))
+	}
+
+	result := &ListResult{
+		Objects:     objects,
+		IsTruncated: truncated,
+		Partial:     len(failedNodes) > 0,
+		FailedNodes: failedNodes,
+	}
+	if truncated {
+		result.NextCursor = encodeListCursor(objects[len(objects)-1].ID)
+	}
+
+	return result, nil
+}
+
+// mergeListings performs a k-way merge of the per-node listings, returning at most
+// limit objects. Objects present on more than one node are only returned once.
+func mergeListings(listings []*nodeListing, limit int) ([]ObjectInfo, bool, []string) {
+	var failed []string
+
+	// advance moves a listing to its next object, reporting false once it is exhausted
+	advance := func(l *nodeListing) bool {
+		obj, ok := <-l.objects
+		if !ok {
+			return false
+		}
+		if obj.Err != nil {
+			failed = append(failed, l.node)
+			l.err = obj.Err
+			return false
+		}
+		l.head = obj
+		return true
+	}
+
+	var active []*nodeListing
+	for _, l := range listings {
+		if advance(l) {
+			active = append(active, l)
+		}
+	}
+
+	objects := make([]ObjectInfo, 0, limit)
+	for len(active) > 0 && len(objects) <= limit {
+		// Find the smallest key among the heads
+		next := active[0].head
+		for _, l := range active[1:] {
+			if l.head.Key < next.Key {
+				next = l.head
+			}
+		}
+		objects = append(objects, toObjectInfo(next))
+
+		// Advance every listing positioned on that key
+		remaining := active[:0]
+		for _, l := range active {
+			if l.head.Key != next.Key || advance(l) {
+				remaining = append(remaining, l)
+			}
+		}
+		active = remaining
+	}
+
+	if len(objects) > limit {
+		return objects[:limit], true, failed
+	}
+	return objects, false, failed
+}
+
+// normalizeListOptions validates the options and returns the effective page size
+// and the key the listing should resume after
+func normalizeListOptions(opts ListOptions) (int, string, error) {
+	if len(opts.Prefix) > 32 {
+		return 0, "", fmt.Errorf("%w: prefix must be at most 32 characters", ErrInvalidListOptions)
+	}
+	for _, char := range opts.Prefix {
+		if !isAlphanumeric(char) {
+			return 0, "", fmt.Errorf("%w: prefix must contain only alphanumeric characters", ErrInvalidListOptions)
+		}
+	}
+
+	limit := opts.Limit
+	if limit < 0 || limit > maxListLimit {
+		return 0, "", fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidListOptions, maxListLimit)
+	}
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+
+	var startAfter string
+	if opts.Cursor != "" {
+		after, err := decodeListCursor(opts.Cursor)
+		if err != nil {
+			return 0, "", err
+		}
+		startAfter = after
+	}
+
+	return limit, startAfter, nil
+}
+
+// encodeListCursor turns the last returned object ID into an opaque continuation token
+func encodeListCursor(lastID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastID))
+}
+
+// decodeListCursor recovers the object ID a listing should resume after
+func decodeListCursor(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || validateObjectID(string(raw)) != nil {
+		return "", fmt.Errorf("%w: invalid cursor", ErrInvalidListOptions)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,109 @@
+package objectStorage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+// newListing feeds the given keys into a closed channel, followed by err if set
+func newListing(node string, err error, keys ...string) *nodeListing {
+	ch := make(chan minio.ObjectInfo, len(keys)+1)
+	for _, key := range keys {
+		ch <- minio.ObjectInfo{Key: key}
+	}
+	if err != nil {
+		ch <- minio.ObjectInfo{Err: err}
+	}
+	close(ch)
+	return &nodeListing{node: node, objects: ch}
+}
+
+func objectIDs(objects []ObjectInfo) []string {
+	ids := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		ids = append(ids, obj.ID)
+	}
+	return ids
+}
+
+func TestMergeListings(t *testing.T) {
+	testCases := []struct {
+		name              string
+		listings          []*nodeListing
+		limit             int
+		expectedIDs       []string
+		expectedTruncated bool
+		expectedFailed    []string
+	}{
+		{
+			name: "Merges Sorted Streams",
+			listings: []*nodeListing{
+				newListing("node-1", nil, "a", "d", "f"),
+				newListing("node-2", nil, "b", "c"),
+				newListing("node-3", nil, "e"),
+			},
+			limit:       10,
+			expectedIDs: []string{"a", "b", "c", "d", "e", "f"},
+		},
+		{
+			name: "Truncates At Limit",
+			listings: []*nodeListing{
+				newListing("node-1", nil, "a", "c"),
+				newListing("node-2", nil, "b", "d"),
+			},
+			limit:             3,
+			expectedIDs:       []string{"a", "b", "c"},
+			expectedTruncated: true,
+		},
+		{
+			name: "Exact Limit Is Not Truncated",
+			listings: []*nodeListing{
+				newListing("node-1", nil, "a"),
+				newListing("node-2", nil, "b"),
+			},
+			limit:       2,
+			expectedIDs: []string{"a", "b"},
+		},
+		{
+			name: "Deduplicates Keys Across Nodes",
+			listings: []*nodeListing{
+				newListing("node-1", nil, "a", "b"),
+				newListing("node-2", nil, "a", "c"),
+			},
+			limit:       10,
+			expectedIDs: []string{"a", "b", "c"},
+		},
+		{
+			name: "Reports Failed Nodes",
+			listings: []*nodeListing{
+				newListing("node-1", nil, "a", "c"),
+				newListing("node-2", errors.New("connection refused")),
+			},
+			limit:          10,
+			expectedIDs:    []string{"a", "c"},
+			expectedFailed: []string{"node-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects, truncated, failed := mergeListings(tc.listings, tc.limit)
+
+			assert.Equal(t, tc.expectedIDs, objectIDs(objects))
+			assert.Equal(t, tc.expectedTruncated, truncated)
+			assert.Equal(t, tc.expectedFailed, failed)
+		})
+	}
+}
+
+func TestListCursorRoundTrip(t *testing.T) {
+	after, err := decodeListCursor(encodeListCursor("object42"))
+	assert.NoError(t, err)
+	assert.Equal(t, "object42", after)
+
+	_, err = decodeListCursor("not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidListOptions)
+}
@@ -0,0 +1,230 @@
+package objectStorage
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	utils "github.com/singhmeghna79/homework-object-storage/pkg"
+	"go.uber.org/zap"
+)
+
+// maxMemoryObjectSize is the largest object kept in memory, the largest single upload S3 accepts
+const maxMemoryObjectSize = 5 << 30
+
+// memoryStorageService keeps objects in memory, for tests and ephemeral caches.
+// With a byte limit the least recently used objects are evicted to make room for new ones.
+type memoryStorageService struct {
+	maxBytes int64
+
+	mutex     sync.Mutex
+	objects   map[string]*list.Element
+	lru       *list.List // memoryObject values, most recently used first
+	usedBytes int64
+
+	logger *zap.Logger
+}
+
+// memoryObject is a stored object. Its data is never modified, a write stores a new object.
+type memoryObject struct {
+	info ObjectInfo
+	data []byte
+}
+
+// NewMemoryStorageService creates an empty in-memory storage holding up to maxBytes of object
+// content, 0 is unlimited
+func NewMemoryStorageService(maxBytes int64, logger *zap.Logger) *memoryStorageService {
+	logger.Info("Storing objects in memory", zap.Int64("max_bytes", maxBytes))
+	return &memoryStorageService{
+		maxBytes: maxBytes,
+		objects:  make(map[string]*list.Element),
+		lru:      list.New(),
+		logger:   logger,
+	}
+}
+
+// PutObject reads the object into memory and evicts the least recently used objects
+// when the byte limit is exceeded
+func (s *memoryStorageService) PutObject(ctx *gin.Context, objectID string, data io.Reader, size int64, opts PutOptions) error {
+	logger := utils.GetLogger(ctx)
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
+	}
+	if s.maxBytes > 0 && size > s.maxBytes {
+		return NewError(ErrTooLarge, fmt.Sprintf("object of %d bytes exceeds the memory limit of %d bytes", size, s.maxBytes), nil)
+	}
+	if size > maxMemoryObjectSize {
+		return NewError(ErrTooLarge, fmt.Sprintf("object of %d bytes exceeds the maximum object size of %d bytes", size, int64(maxMemoryObjectSize)), nil)
+	}
+
+	// The buffer grows with the data received, a size the client made up does not allocate anything
+	var buffer bytes.Buffer
+	read, err := buffer.ReadFrom(io.LimitReader(data, size+1))
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+	if read != size {
+		return fmt.Errorf("failed to read object: received %d bytes, expected %d", read, size)
+	}
+	// The buffer may have grown to twice the object size, only the content counts towards the limit
+	content := make([]byte, read)
+	copy(content, buffer.Bytes())
+	hash := md5.Sum(content)
+
+	object := &memoryObject{
+		info: ObjectInfo{
+			ID:                 objectID,
+			Size:               size,
+			ETag:               hex.EncodeToString(hash[:]),
+			LastModified:       time.Now().UTC(),
+			ContentType:        opts.ContentType,
+			ContentDisposition: opts.ContentDisposition,
+			CacheControl:       opts.CacheControl,
+			UserMetadata:       maps.Clone(opts.UserMetadata),
+		},
+		data: content,
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current, exists := s.objects[objectID]
+	var currentInfo *ObjectInfo
+	if exists {
+		currentInfo = &current.Value.(*memoryObject).info
+	}
+	if err := opts.checkConditions(currentInfo); err != nil {
+		return err
+	}
+
+	if exists {
+		s.remove(current)
+	}
+	s.objects[objectID] = s.lru.PushFront(object)
+	s.usedBytes += size
+
+	for s.maxBytes > 0 && s.usedBytes > s.maxBytes {
+		evicted := s.lru.Back()
+		s.remove(evicted)
+		logger.Info("Evicted object from memory", zap.String("object_id", evicted.Value.(*memoryObject).info.ID))
+	}
+
+	logger.Info("Stored object in memory", zap.String("object_id", objectID), zap.Int64("size", size))
+	return nil
+}
+
+// remove drops an object, the caller holds the mutex
+func (s *memoryStorageService) remove(element *list.Element) {
+	object := s.lru.Remove(element).(*memoryObject)
+	delete(s.objects, object.info.ID)
+	s.usedBytes -= object.info.Size
+}
+
+// lookup returns an object, marking it as recently used when touch is set
+func (s *memoryStorageService) lookup(objectID string, touch bool) (*memoryObject, error) {
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.objects[objectID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if touch {
+		s.lru.MoveToFront(element)
+	}
+	return element.Value.(*memoryObject), nil
+}
+
+// GetObject returns the object and marks it as recently used
+func (s *memoryStorageService) GetObject(ctx *gin.Context, objectID string, opts GetOptions) (io.ReadCloser, ObjectInfo, error) {
+	object, err := s.lookup(objectID, true)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	info, data := object.objectInfo(), object.data
+	if opts.Range != nil {
+		if opts.Range.Start < 0 || opts.Range.Start > opts.Range.End || opts.Range.Start >= info.Size {
+			return nil, ObjectInfo{}, NewError(ErrInvalidRange, fmt.Sprintf("invalid range: %d-%d of %d bytes", opts.Range.Start, opts.Range.End, info.Size), nil)
+		}
+		data = data[opts.Range.Start:min(opts.Range.End+1, info.Size)]
+		info.Size = int64(len(data))
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), info, nil
+}
+
+// StatObject returns the metadata of an object without counting as a use of it
+func (s *memoryStorageService) StatObject(ctx *gin.Context, objectID string) (ObjectInfo, error) {
+	object, err := s.lookup(objectID, false)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return object.objectInfo(), nil
+}
+
+// DeleteObject removes an object. Deleting an object that does not exist is not an error.
+func (s *memoryStorageService) DeleteObject(ctx *gin.Context, objectID string) error {
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.objects[objectID]; ok {
+		s.remove(element)
+	}
+	return nil
+}
+
+// ListObjects returns a page of the stored objects sorted by ID
+func (s *memoryStorageService) ListObjects(ctx *gin.Context, opts ListOptions) (*ListResult, error) {
+	limit, startAfter, err := normalizeListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	var objects []ObjectInfo
+	for id, element := range s.objects {
+		if strings.HasPrefix(id, opts.Prefix) && id > startAfter {
+			objects = append(objects, element.Value.(*memoryObject).info.listed())
+		}
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].ID < objects[j].ID })
+
+	result := &ListResult{Objects: []ObjectInfo{}}
+	if len(objects) > limit {
+		objects = objects[:limit]
+		result.IsTruncated = true
+		result.NextCursor = encodeListCursor(objects[limit-1].ID)
+	}
+	result.Objects = append(result.Objects, objects...)
+	return result, nil
+}
+
+// objectInfo returns the metadata of the object, safe for the caller to modify
+func (o *memoryObject) objectInfo() ObjectInfo {
+	info := o.info
+	info.UserMetadata = maps.Clone(o.info.UserMetadata)
+	return info
+}
@@ -0,0 +1,159 @@
+package objectStorage
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestMemoryService(maxBytes int64) (*memoryStorageService, *gin.Context) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	return NewMemoryStorageService(maxBytes, zap.NewNop()), ctx
+}
+
+func TestMemoryRoundTrip(t *testing.T) {
+	service, ctx := newTestMemoryService(0)
+	content := "This is a test object"
+	metadata := map[string]string{"Owner": "sync"}
+
+	require.NoError(t, putString(ctx, service, "test123", content, PutOptions{ContentType: "text/plain", UserMetadata: metadata}))
+	// The caller's metadata is copied
+	metadata["Owner"] = "changed"
+
+	obj, info, err := service.GetObject(ctx, "test123", GetOptions{})
+	require.NoError(t, err)
+	data, _ := io.ReadAll(obj)
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, map[string]string{"Owner": "sync"}, info.UserMetadata)
+
+	obj, info, err = service.GetObject(ctx, "test123", GetOptions{Range: &ByteRange{Start: 10, End: 100}})
+	require.NoError(t, err)
+	data, _ = io.ReadAll(obj)
+	assert.Equal(t, "test object", string(data))
+	assert.Equal(t, int64(11), info.Size)
+	_, _, err = service.GetObject(ctx, "test123", GetOptions{Range: &ByteRange{Start: 21, End: 30}})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	stat, err := service.StatObject(ctx, "test123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), stat.Size)
+
+	require.NoError(t, service.DeleteObject(ctx, "test123"))
+	require.NoError(t, service.DeleteObject(ctx, "test123"))
+	_, _, err = service.GetObject(ctx, "test123", GetOptions{})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Zero(t, service.usedBytes)
+
+	_, err = service.StatObject(ctx, "not-valid")
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
+
+func TestMemoryObjectSize(t *testing.T) {
+	service, ctx := newTestMemoryService(0)
+
+	// The size announced by the client is not allocated up front
+	err := service.PutObject(ctx, "test123", strings.NewReader("short"), 1<<40, PutOptions{})
+	assert.ErrorIs(t, err, ErrTooLarge)
+	err = service.PutObject(ctx, "test123", strings.NewReader("short"), maxMemoryObjectSize, PutOptions{})
+	assert.EqualError(t, err, "failed to read object: received 5 bytes, expected 5368709120")
+	err = service.PutObject(ctx, "test123", strings.NewReader("longer than announced"), 5, PutOptions{})
+	assert.EqualError(t, err, "failed to read object: received 6 bytes, expected 5")
+
+	_, err = service.StatObject(ctx, "test123")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Zero(t, service.usedBytes)
+
+	// Only the content is kept, not the spare capacity of the upload buffer
+	content := strings.Repeat("a", 100_000)
+	require.NoError(t, putString(ctx, service, "test123", content, PutOptions{}))
+	object, err := service.lookup("test123", false)
+	require.NoError(t, err)
+	assert.Equal(t, len(content), cap(object.data))
+}
+
+func TestMemoryEviction(t *testing.T) {
+	service, ctx := newTestMemoryService(10)
+
+	require.NoError(t, putString(ctx, service, "first", "aaaa", PutOptions{}))
+	require.NoError(t, putString(ctx, service, "second", "bbbb", PutOptions{}))
+
+	// Reading first makes second the least recently used, a stat does not count
+	_, _, err := service.GetObject(ctx, "first", GetOptions{})
+	require.NoError(t, err)
+	_, err = service.StatObject(ctx, "second")
+	require.NoError(t, err)
+
+	require.NoError(t, putString(ctx, service, "third", "cccc", PutOptions{}))
+	_, err = service.StatObject(ctx, "second")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.StatObject(ctx, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), service.usedBytes)
+
+	// Overwriting an object replaces its size instead of adding to it
+	require.NoError(t, putString(ctx, service, "third", "cc", PutOptions{}))
+	assert.Equal(t, int64(6), service.usedBytes)
+
+	err = putString(ctx, service, "huge", strings.Repeat("x", 11), PutOptions{})
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.EqualError(t, err, "object of 11 bytes exceeds the memory limit of 10 bytes")
+}
+
+func TestMemoryConditionalPut(t *testing.T) {
+	service, ctx := newTestMemoryService(0)
+	require.NoError(t, putString(ctx, service, "existing", "v1", PutOptions{}))
+	current, _ := service.StatObject(ctx, "existing")
+
+	assert.ErrorIs(t, putString(ctx, service, "existing", "v2", PutOptions{IfNoneMatch: "*"}), ErrPreconditionFailed)
+	assert.ErrorIs(t, putString(ctx, service, "existing", "v2", PutOptions{IfMatch: "0123"}), ErrPreconditionFailed)
+	assert.ErrorIs(t, putString(ctx, service, "missing", "v2", PutOptions{IfMatch: "*"}), ErrPreconditionFailed)
+	assert.NoError(t, putString(ctx, service, "existing", "v2", PutOptions{IfMatch: current.ETag}))
+
+	// Exactly one of racing create-only writes wins
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	succeeded := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if putString(ctx, service, "contended", "data", PutOptions{IfNoneMatch: "*"}) == nil {
+				mutex.Lock()
+				succeeded++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestMemoryListObjects(t *testing.T) {
+	service, ctx := newTestMemoryService(0)
+	for _, id := range []string{"b2", "a1", "c3", "a2", "a3"} {
+		require.NoError(t, putString(ctx, service, id, "content", PutOptions{}))
+	}
+
+	first, err := service.ListObjects(ctx, ListOptions{Prefix: "a", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2"}, objectIDs(first.Objects))
+	assert.True(t, first.IsTruncated)
+
+	second, err := service.ListObjects(ctx, ListOptions{Prefix: "a", Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a3"}, objectIDs(second.Objects))
+	assert.False(t, second.IsTruncated)
+
+	empty, err := service.ListObjects(ctx, ListOptions{Prefix: "z"})
+	require.NoError(t, err)
+	assert.NotNil(t, empty.Objects)
+	assert.Empty(t, empty.Objects)
+}
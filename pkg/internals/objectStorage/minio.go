@@ -2,8 +2,8 @@ package objectStorage
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"hash/fnv"
 	"io"
 	"sync"
 	"time"
@@ -13,6 +13,7 @@ import (
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	utils "github.com/singhmeghna79/homework-object-storage/pkg"
 	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/placement"
 	"go.uber.org/zap"
 )
 
@@ -22,39 +23,114 @@ const bucketName = "objects"
 type minioStorageService struct {
 	nodes        []docker.MinioNode
 	clients      map[string]*minio.Client
+	clientErrors map[string]error
+	breakers     map[string]*circuitBreaker
 	clientsMutex sync.RWMutex
-	logger       *zap.Logger
+	placement    placement.Placement
+	// previousPlacement is the placement before the last topology change, reads fall back to it
+	previousPlacement         placement.Placement
+	previousReplicationFactor int
+	rebalancer                *rebalancer
+	health                    *healthChecker
+	// connecting cancels the background initialisation of the clients that are not ready yet
+	connecting map[string]*connectAttempt
+	// topologyMutex serializes updates of the node set
+	topologyMutex sync.Mutex
+	topologyHooks []func(TopologyChange)
+	// repairs holds the IDs of objects being repaired on read
+	repairs sync.Map
+	config  Config
+	logger  *zap.Logger
 }
 
 // NewService creates a new gateway service
-func NewminioStorageService(nodes []docker.MinioNode, logger *zap.Logger) *minioStorageService {
+func NewminioStorageService(nodes []docker.MinioNode, config Config, logger *zap.Logger) *minioStorageService {
 	service := &minioStorageService{
-		nodes:   nodes,
-		clients: make(map[string]*minio.Client),
-		logger:  logger,
+		nodes:        nodes,
+		clients:      make(map[string]*minio.Client),
+		clientErrors: make(map[string]error),
+		breakers:     make(map[string]*circuitBreaker),
+		connecting:   make(map[string]*connectAttempt),
+		placement:    newPlacement(nodes, config),
+		config:       config,
+		logger:       logger,
+	}
+	service.rebalancer = newRebalancer(service, RebalanceThrottle{
+		ObjectsPerSecond: config.RebalanceObjectsPerSecond,
+		BytesPerSecond:   config.RebalanceBytesPerSecond,
+	}, logger)
+	if config.RebalanceOnChange {
+		service.OnTopologyChange(service.rebalanceOnChange)
+	}
+	service.health = newHealthChecker(service, config, logger)
+	if config.HealthCheckInterval > 0 {
+		go service.health.run(context.Background())
+	}
+
+	if config.ReplicationFactor > len(nodes) {
+		logger.Warn("Replication factor exceeds the number of nodes",
+			zap.Int("replication_factor", config.ReplicationFactor), zap.Int("minio nodes", len(nodes)))
 	}
 
-	// Initialize Minio clients for each node
+	// Clients are initialised in the background so the gateway serves requests right away
 	for _, node := range nodes {
+		service.connect(node)
+	}
 
-		for i := 0; i < 5; i++ {
-			err := service.initializeClient(node)
-			if err == nil {
-				break
-			}
-			logger.Info("Retrying MinIO client init ...", zap.Int("retry", i+1))
-			time.Sleep(10 * time.Second)
-		}
-		if err := service.initializeClient(node); err != nil {
-			logger.Error("Failed to initialize client for node ", zap.String("node_name", node.Name), zap.Error(err))
+	return service
+}
+
+// Rebalancer returns the rebalancer migrating objects between the nodes
+func (s *minioStorageService) Rebalancer() Rebalancer {
+	return s.rebalancer
+}
+
+// HealthChecker returns the checker probing the nodes
+func (s *minioStorageService) HealthChecker() HealthChecker {
+	return s.health
+}
+
+// currentNodes returns a copy of the current node set
+func (s *minioStorageService) currentNodes() []docker.MinioNode {
+	s.clientsMutex.RLock()
+	defer s.clientsMutex.RUnlock()
+	return append(s.nodes[:0:0], s.nodes...)
+}
+
+// readReplicas returns the replicas reads are sent to. Unhealthy replicas are skipped when enough
+// healthy ones remain for the read quorum, otherwise they are asked last.
+func (s *minioStorageService) readReplicas(replicas []replica, readQuorum int) []replica {
+	healthy := make([]replica, 0, len(replicas))
+	var unhealthy []replica
+	for _, r := range replicas {
+		if s.health.isHealthy(r.node.ID) {
+			healthy = append(healthy, r)
+		} else {
+			unhealthy = append(unhealthy, r)
 		}
 	}
 
-	return service
+	if len(healthy) >= readQuorum {
+		return healthy
+	}
+	return append(healthy, unhealthy...)
 }
 
-// initializeClient creates a Minio client for a given node
-func (s *minioStorageService) initializeClient(node docker.MinioNode) error {
+// initializeClient creates a Minio client for a given node. Nothing is stored once ctx is cancelled.
+func (s *minioStorageService) initializeClient(ctx context.Context, node docker.MinioNode) (err error) {
+	// The last failure is reported by the readiness check
+	defer func() {
+		s.clientsMutex.Lock()
+		if ctx.Err() != nil {
+			// The attempt was abandoned, the node left or its endpoint changed
+		} else if err != nil {
+			s.clientErrors[node.ID] = err
+		} else {
+			delete(s.clientErrors, node.ID)
+		}
+		s.clientsMutex.Unlock()
+	}()
+
 	endpoint := fmt.Sprintf("%s:%s", node.IPAddress, node.Port)
 	client, err := minio.New(endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(node.AccessKey, node.SecretKey, ""),
@@ -65,16 +141,16 @@ func (s *minioStorageService) initializeClient(node docker.MinioNode) error {
 	}
 
 	// Create the bucket if it doesn't exist
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	bucketCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	exists, err := client.BucketExists(ctx, bucketName)
+	exists, err := client.BucketExists(bucketCtx, bucketName)
 	if err != nil {
 		return fmt.Errorf("failed to check if bucket exists: %w", err)
 	}
 
 	if !exists {
-		err = client.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{})
+		err = client.MakeBucket(bucketCtx, bucketName, minio.MakeBucketOptions{})
 		if err != nil {
 			return fmt.Errorf("failed to create bucket: %w", err)
 		}
@@ -82,108 +158,333 @@ func (s *minioStorageService) initializeClient(node docker.MinioNode) error {
 	}
 
 	s.clientsMutex.Lock()
+	if ctx.Err() != nil {
+		s.clientsMutex.Unlock()
+		return ctx.Err()
+	}
 	s.clients[node.ID] = client
+	if _, ok := s.breakers[node.ID]; !ok {
+		s.breakers[node.ID] = newCircuitBreaker(node.Name, s.config, s.logger)
+	}
 	s.clientsMutex.Unlock()
 
 	s.logger.Info("Initialized client for node at ", zap.String("node_name", node.Name), zap.String("endpoint", endpoint))
 	return nil
 }
 
-// getNodeForID consistently maps an object ID to a specific node
-func (s *minioStorageService) getNodeForID(objectID string) (docker.MinioNode, *minio.Client, error) {
+// getReplicasForID consistently maps an object ID to the distinct nodes holding its replicas,
+// as chosen by the configured placement strategy
+func (s *minioStorageService) getReplicasForID(objectID string) ([]replica, error) {
+	s.clientsMutex.RLock()
+	defer s.clientsMutex.RUnlock()
+
 	if len(s.nodes) == 0 {
-		return docker.MinioNode{}, nil, fmt.Errorf("no storage nodes available")
+		return nil, NewError(ErrUnavailable, "no storage nodes available", nil)
 	}
 
-	// Use a simple hash function to consistently map the object ID to a node
-	h := fnv.New32a()
-	h.Write([]byte(objectID))
-	nodeIndex := int(h.Sum32()) % len(s.nodes)
-	node := s.nodes[nodeIndex]
+	owners := s.placement.Locate(objectID, s.replicationFactor())
+	replicas := make([]replica, 0, len(owners))
+	for _, nodeID := range owners {
+		node, _ := s.nodeByID(nodeID)
+		replicas = append(replicas, s.replicaOf(node))
+	}
 
-	s.clientsMutex.RLock()
-	client, exists := s.clients[node.ID]
-	s.clientsMutex.RUnlock()
+	return replicas, nil
+}
 
-	if !exists {
-		return node, nil, fmt.Errorf("client for node %s not initialized", node.Name)
+// replicaOf returns the client and circuit breaker of a node. The caller must hold clientsMutex.
+func (s *minioStorageService) replicaOf(node docker.MinioNode) replica {
+	return replica{node: node, client: s.clients[node.ID], breaker: s.breakers[node.ID]}
+}
+
+// nodeByID finds a node of the current topology. The caller must hold clientsMutex.
+func (s *minioStorageService) nodeByID(nodeID string) (docker.MinioNode, bool) {
+	for _, node := range s.nodes {
+		if node.ID == nodeID {
+			return node, true
+		}
+	}
+	return docker.MinioNode{}, false
+}
+
+// newPlacement builds the configured placement strategy for a set of nodes.
+// The strategy is checked by Config.Validate, unknown names fall back to the ring.
+func newPlacement(nodes []docker.MinioNode, config Config) placement.Placement {
+	members := make([]placement.Member, 0, len(nodes))
+	for _, node := range nodes {
+		members = append(members, placement.Member{ID: node.ID, Weight: node.Weight})
 	}
 
-	return node, client, nil
+	p, err := placement.New(config.Placement, members, config.VirtualNodes)
+	if err != nil {
+		return placement.NewRing(members, config.VirtualNodes)
+	}
+	return p
 }
 
-// PutObject stores an object in the appropriate node
-func (s *minioStorageService) PutObject(ctx *gin.Context, objectID string, data io.Reader, size int64) error {
+// replicationFactor returns the number of replicas per object, which cannot exceed the number of nodes.
+// The caller must hold clientsMutex.
+func (s *minioStorageService) replicationFactor() int {
+	return min(s.config.ReplicationFactor, len(s.nodes))
+}
+
+// quorums returns the write and read quorums for a set of replicas
+func (s *minioStorageService) quorums(replicas []replica) (int, int) {
+	return min(s.config.WriteQuorum, len(replicas)), min(s.config.ReadQuorum, len(replicas))
+}
+
+// PutObject stores an object on all of its replicas
+func (s *minioStorageService) PutObject(ctx *gin.Context, objectID string, data io.Reader, size int64, opts PutOptions) error {
 	logger := utils.GetLogger(ctx)
 	// Validate object ID
 	if err := validateObjectID(objectID); err != nil {
 		return err
 	}
 
-	// Get the appropriate node and client
-	node, client, err := s.getNodeForID(objectID)
+	// Get the appropriate nodes and clients
+	replicas, err := s.getReplicasForID(objectID)
 	if err != nil {
 		return err
 	}
+	writeQuorum, _ := s.quorums(replicas)
 
-	logger.Info("Storing object on node ", zap.String("object_id", objectID), zap.String("node_name", node.Name))
+	logger.Info("Storing object on nodes ", zap.String("object_id", objectID), zap.Strings("node_names", replicaNames(replicas)))
+
+	putOpts := minio.PutObjectOptions{
+		ContentType:        opts.ContentType,
+		ContentDisposition: opts.ContentDisposition,
+		CacheControl:       opts.CacheControl,
+		UserMetadata:       opts.UserMetadata,
+	}
+	// Each replica evaluates the conditions against its own copy. With a single replica racing writers
+	// cannot overwrite each other, with more they can each win on some replicas. Replicas that accepted
+	// a write that failed elsewhere are not rolled back, reads then see the version a quorum agrees on.
+	if opts.IfMatch != "" {
+		putOpts.SetMatchETag(opts.IfMatch)
+	}
+	if opts.IfNoneMatch != "" {
+		putOpts.SetMatchETagExcept(opts.IfNoneMatch)
+	}
 
 	// Upload the object
-	_, err = client.PutObject(ctx, bucketName, objectID, data, size, minio.PutObjectOptions{})
+	return s.putReplicated(ctx, replicas, objectID, data, size, putOpts, writeQuorum)
+}
+
+// GetObject retrieves an object from its replicas once enough of them agree on its version.
+// Objects the replicas do not have are looked up on the nodes that held them before a topology change.
+func (s *minioStorageService) GetObject(ctx *gin.Context, objectID string, opts GetOptions) (io.ReadCloser, ObjectInfo, error) {
+	logger := utils.GetLogger(ctx)
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	// Get the appropriate nodes and clients
+	replicas, err := s.getReplicasForID(objectID)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	_, readQuorum := s.quorums(replicas)
+
+	// Without a read quorum the first replica that answers is used
+	candidates, etag := s.readReplicas(replicas, readQuorum), ""
+	if readQuorum > 1 {
+		info, agreeing, err := s.statQuorum(ctx, candidates, objectID, readQuorum)
+		if err == nil {
+			// Make sure the version the replicas agreed on is the one that is read
+			candidates, etag = agreeing, info.ETag
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, ObjectInfo{}, err
+		}
+	}
+
+	if etag != "" || readQuorum <= 1 {
+		obj, info, err := s.readObject(ctx, logger, candidates, objectID, opts, etag)
+		if !errors.Is(err, ErrNotFound) {
+			return obj, info, err
+		}
+	}
+
+	// The object may not have been moved to its new replicas yet
+	source, sourceInfo, err := s.findMisplaced(ctx, objectID, replicas)
 	if err != nil {
-		return fmt.Errorf("failed to store object: %w", err)
+		return nil, ObjectInfo{}, err
 	}
+	s.repairOnRead(objectID, source, sourceInfo.ETag, replicas)
 
-	return nil
+	return s.readObject(ctx, logger, []replica{source}, objectID, opts, sourceInfo.ETag)
 }
 
-// GetObject retrieves an object from the appropriate node
-func (s *minioStorageService) GetObject(ctx *gin.Context, objectID string) (io.ReadCloser, error) {
+// readObject reads an object from the first candidate that answers. When etag is set only that
+// version is accepted. The object is only reported missing once every candidate lacks it.
+func (s *minioStorageService) readObject(ctx context.Context, logger *zap.Logger, candidates []replica, objectID string, opts GetOptions, etag string) (io.ReadCloser, ObjectInfo, error) {
+	getOpts := minio.GetObjectOptions{}
+	if opts.Range != nil {
+		if err := getOpts.SetRange(opts.Range.Start, opts.Range.End); err != nil {
+			return nil, ObjectInfo{}, NewError(ErrInvalidRange, "invalid range", err)
+		}
+	}
+	if etag != "" {
+		if err := getOpts.SetMatchETag(etag); err != nil {
+			return nil, ObjectInfo{}, fmt.Errorf("failed to get object: %w", err)
+		}
+	}
+
+	lastErr := fmt.Errorf("no storage nodes available")
+	var missing int
+	for _, r := range candidates {
+		if r.client == nil {
+			lastErr = fmt.Errorf("client for node %s not initialized", r.node.Name)
+			continue
+		}
+
+		logger.Info("Retrieving object from node ", zap.String("object_id", objectID), zap.String("node_name", r.node.Name))
+
+		// Get the object. The core client sends a single request, the object reader of the
+		// client would drop the range once its stats are read.
+		var obj io.ReadCloser
+		var info minio.ObjectInfo
+		err := r.call(func() (err error) {
+			obj, info, _, err = minio.Core{Client: r.client}.GetObject(ctx, bucketName, objectID, getOpts)
+			return err
+		})
+		if err != nil {
+			if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+				// A lagging replica may miss an object the others hold
+				missing++
+				continue
+			}
+			if minio.ToErrorResponse(err).Code == "InvalidRange" {
+				// The object may have shrunk since the range was checked against its size
+				return nil, ObjectInfo{}, NewError(ErrInvalidRange, "", err)
+			}
+			lastErr = fmt.Errorf("failed to get object: %w", err)
+			continue
+		}
+
+		return obj, toObjectInfo(info), nil
+	}
+
+	// Any candidate may serve the object, so it is only missing when every candidate lacks it
+	if missing == len(candidates) {
+		return nil, ObjectInfo{}, ErrNotFound
+	}
+	return nil, ObjectInfo{}, NewError(ErrUnavailable, "no replica could serve the object", lastErr)
+}
+
+// StatObject returns the metadata of an object without retrieving its content
+func (s *minioStorageService) StatObject(ctx *gin.Context, objectID string) (ObjectInfo, error) {
 	logger := utils.GetLogger(ctx)
 	// Validate object ID
 	if err := validateObjectID(objectID); err != nil {
-		return nil, err
+		return ObjectInfo{}, err
 	}
 
-	// Get the appropriate node and client
-	node, client, err := s.getNodeForID(objectID)
+	// Get the appropriate nodes and clients
+	replicas, err := s.getReplicasForID(objectID)
 	if err != nil {
-		return nil, err
+		return ObjectInfo{}, err
 	}
+	_, readQuorum := s.quorums(replicas)
 
-	logger.Info("Retrieving object from node ", zap.String("object_id", objectID), zap.String("node_name", node.Name))
+	logger.Info("Retrieving object info from nodes ", zap.String("object_id", objectID), zap.Strings("node_names", replicaNames(replicas)))
 
-	// Get the object
-	obj, err := client.GetObject(ctx, bucketName, objectID, minio.GetObjectOptions{})
+	info, _, err := s.statQuorum(ctx, s.readReplicas(replicas, readQuorum), objectID, readQuorum)
+	if errors.Is(err, ErrNotFound) {
+		// The object may not have been moved to its new replicas yet
+		var source replica
+		source, info, err = s.findMisplaced(ctx, objectID, replicas)
+		if err == nil {
+			s.repairOnRead(objectID, source, info.ETag, replicas)
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get object: %w", err)
+		return ObjectInfo{}, err
+	}
+
+	return toObjectInfo(info), nil
+}
+
+// DeleteObject removes an object from all of its replicas. Deleting an object
+// that does not exist is not an error, so the operation is idempotent.
+func (s *minioStorageService) DeleteObject(ctx *gin.Context, objectID string) error {
+	logger := utils.GetLogger(ctx)
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
 	}
 
-	// Check if the object exists by attempting to get its stats
-	_, err = obj.Stat()
+	// Get the appropriate nodes and clients
+	replicas, err := s.getReplicasForID(objectID)
 	if err != nil {
-		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
-			return nil, fmt.Errorf("object not found")
+		return err
+	}
+	writeQuorum, _ := s.quorums(replicas)
+
+	logger.Info("Deleting object from nodes ", zap.String("object_id", objectID), zap.Strings("node_names", replicaNames(replicas)))
+
+	// Minio reports success for keys that are already gone
+	if err := s.deleteReplicated(ctx, replicas, objectID, writeQuorum); err != nil {
+		return err
+	}
+
+	// Copies that have not been migrated yet would otherwise be found by the read fallback
+	s.deleteMisplaced(ctx, objectID, replicas)
+	return nil
+}
+
+// replicaNames returns the node names of the replicas for logging
+func replicaNames(replicas []replica) []string {
+	names := make([]string, 0, len(replicas))
+	for _, r := range replicas {
+		names = append(names, r.node.Name)
+	}
+	return names
+}
+
+// toObjectInfo converts the Minio object metadata into the storage representation
+func toObjectInfo(info minio.ObjectInfo) ObjectInfo {
+	objectInfo := ObjectInfo{
+		ID:           info.Key,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		ContentType:  info.ContentType,
+	}
+
+	// Listings do not carry the remaining metadata
+	if info.Metadata != nil {
+		objectInfo.ContentDisposition = info.Metadata.Get("Content-Disposition")
+		objectInfo.CacheControl = info.Metadata.Get("Cache-Control")
+	}
+	if len(info.UserMetadata) > 0 {
+		objectInfo.UserMetadata = make(map[string]string, len(info.UserMetadata))
+		for key, value := range info.UserMetadata {
+			objectInfo.UserMetadata[key] = value
 		}
-		return nil, fmt.Errorf("failed to stat object: %w", err)
 	}
 
-	return obj, nil
+	return objectInfo
 }
 
 // validateObjectID ensures the object ID meets the requirements
 func validateObjectID(id string) error {
 	if len(id) == 0 || len(id) > 32 {
-		return fmt.Errorf("object ID must be between 1 and 32 characters")
+		return NewError(ErrInvalidID, "object ID must be between 1 and 32 characters", nil)
 	}
 
 	for _, char := range id {
-		if !((char >= 'a' && char <= 'z') ||
-			(char >= 'A' && char <= 'Z') ||
-			(char >= '0' && char <= '9')) {
-			return fmt.Errorf("object ID must contain only alphanumeric characters")
+		if !isAlphanumeric(char) {
+			return NewError(ErrInvalidID, "object ID must contain only alphanumeric characters", nil)
 		}
 	}
 
 	return nil
 }
+
+// isAlphanumeric reports whether char is an ASCII letter or digit
+func isAlphanumeric(char rune) bool {
+	return (char >= 'a' && char <= 'z') ||
+		(char >= 'A' && char <= 'Z') ||
+		(char >= '0' && char <= '9')
+}
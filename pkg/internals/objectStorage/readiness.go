@@ -0,0 +1,62 @@
+package objectStorage
+
+//go:generate counterfeiter -o fakes/InterfaceReadinessChecker.go --fake-name InterfaceReadinessChecker . ReadinessChecker
+type ReadinessChecker interface {
+	// Readiness reports whether the backend can serve requests
+	Readiness() Readiness
+}
+
+// Readiness reports whether enough nodes are ready to accept writes
+type Readiness struct {
+	Ready         bool          `json:"ready"`
+	ReadyNodes    int           `json:"ready_nodes"`
+	RequiredNodes int           `json:"required_nodes"`
+	FailingNodes  []FailingNode `json:"failing_nodes,omitempty"`
+}
+
+// FailingNode is a node that cannot serve requests and the reason why
+type FailingNode struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Readiness counts the nodes whose client was initialised, which includes the bucket check, and
+// that pass their health checks. The backend is ready once they can reach the write quorum.
+func (s *minioStorageService) Readiness() Readiness {
+	s.clientsMutex.RLock()
+	nodes := append(s.nodes[:0:0], s.nodes...)
+	clients := make(map[string]bool, len(s.clients))
+	for id := range s.clients {
+		clients[id] = true
+	}
+	clientErrors := make(map[string]error, len(s.clientErrors))
+	for id, err := range s.clientErrors {
+		clientErrors[id] = err
+	}
+	s.clientsMutex.RUnlock()
+
+	health := make(map[string]NodeHealth, len(nodes))
+	for _, entry := range s.health.NodeHealth() {
+		health[entry.ID] = entry
+	}
+
+	// Writes to fewer nodes than the write quorum succeed when the cluster is that small
+	readiness := Readiness{RequiredNodes: max(min(s.config.WriteQuorum, len(nodes)), 1)}
+	for _, node := range nodes {
+		switch {
+		case !clients[node.ID]:
+			reason := "client not initialized"
+			if err := clientErrors[node.ID]; err != nil {
+				reason = err.Error()
+			}
+			readiness.FailingNodes = append(readiness.FailingNodes, FailingNode{Name: node.Name, Reason: reason})
+		case !health[node.ID].Healthy:
+			readiness.FailingNodes = append(readiness.FailingNodes, FailingNode{Name: node.Name, Reason: health[node.ID].LastError})
+		default:
+			readiness.ReadyNodes++
+		}
+	}
+	readiness.Ready = readiness.ReadyNodes >= readiness.RequiredNodes
+
+	return readiness
+}
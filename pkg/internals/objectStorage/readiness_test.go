@@ -0,0 +1,48 @@
+package objectStorage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	nodes := newTestNodes(3)
+	config := DefaultConfig()
+	config.ReplicationFactor, config.WriteQuorum, config.ReadQuorum = 3, 2, 2
+	service := newTestService(nodes, config)
+
+	// No client could be initialised yet
+	service.clientErrors[nodes[0].ID] = errors.New("failed to check if bucket exists: connection refused")
+	readiness := service.Readiness()
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, 0, readiness.ReadyNodes)
+	assert.Equal(t, 2, readiness.RequiredNodes)
+	assert.Equal(t, []FailingNode{
+		{Name: "node-1", Reason: "failed to check if bucket exists: connection refused"},
+		{Name: "node-2", Reason: "client not initialized"},
+		{Name: "node-3", Reason: "client not initialized"},
+	}, readiness.FailingNodes)
+
+	// Two nodes are enough for the write quorum, unhealthy nodes do not count
+	for _, node := range nodes {
+		service.clients[node.ID] = &minio.Client{}
+	}
+	for i := 0; i < config.UnhealthyThreshold; i++ {
+		service.health.record(nodes[1], errors.New("liveness check returned 503 Service Unavailable"))
+	}
+	readiness = service.Readiness()
+	assert.True(t, readiness.Ready)
+	assert.Equal(t, 2, readiness.ReadyNodes)
+	assert.Equal(t, []FailingNode{{Name: "node-2", Reason: "liveness check returned 503 Service Unavailable"}}, readiness.FailingNodes)
+
+	for i := 0; i < config.UnhealthyThreshold; i++ {
+		service.health.record(nodes[2], errors.New("timeout"))
+	}
+	assert.False(t, service.Readiness().Ready)
+
+	// Without nodes nothing can be stored
+	assert.False(t, newTestService(nil, config).Readiness().Ready)
+}
@@ -0,0 +1,383 @@
+package objectStorage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+// States of a rebalance run
+const (
+	RebalanceIdle      = "idle"
+	RebalanceRunning   = "running"
+	RebalanceCompleted = "completed"
+	RebalanceCancelled = "cancelled"
+	RebalanceFailed    = "failed"
+)
+
+// ErrRebalanceRunning is returned when a rebalance is started while another one is running
+var ErrRebalanceRunning = NewError(ErrConflict, "rebalance already running", nil)
+
+//go:generate counterfeiter -o fakes/InterfaceRebalancer.go --fake-name InterfaceRebalancer . Rebalancer
+type Rebalancer interface {
+	// Start begins migrating misplaced objects in the background
+	Start() error
+	// Stop cancels the running migration, objects already moved stay moved
+	Stop()
+	Status() RebalanceStatus
+	SetThrottle(throttle RebalanceThrottle) error
+}
+
+// RebalancerProvider is implemented by backends that can migrate objects after topology changes
+type RebalancerProvider interface {
+	Rebalancer() Rebalancer
+}
+
+// RebalanceThrottle limits how fast objects are migrated. Zero values mean unlimited.
+type RebalanceThrottle struct {
+	ObjectsPerSecond float64 `json:"objects_per_second"`
+	BytesPerSecond   int64   `json:"bytes_per_second"`
+}
+
+// RebalanceStatus reports the progress of the current or last rebalance
+type RebalanceStatus struct {
+	State       string            `json:"state"`
+	StartedAt   *time.Time        `json:"started_at,omitempty"`
+	FinishedAt  *time.Time        `json:"finished_at,omitempty"`
+	CurrentNode string            `json:"current_node,omitempty"`
+	Scanned     int64             `json:"scanned"`
+	Misplaced   int64             `json:"misplaced"`
+	Moved       int64             `json:"moved"`
+	Failed      int64             `json:"failed"`
+	BytesMoved  int64             `json:"bytes_moved"`
+	LastError   string            `json:"last_error,omitempty"`
+	Throttle    RebalanceThrottle `json:"throttle"`
+}
+
+// rebalancer walks the bucket of every node and moves each object that is stored on a node
+// the current placement no longer assigns it to. A copy is verified on every node that should
+// hold the object before the misplaced copy is deleted.
+type rebalancer struct {
+	service *minioStorageService
+	logger  *zap.Logger
+
+	mutex  sync.Mutex
+	status RebalanceStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newRebalancer(service *minioStorageService, throttle RebalanceThrottle, logger *zap.Logger) *rebalancer {
+	return &rebalancer{
+		service: service,
+		logger:  logger,
+		status:  RebalanceStatus{State: RebalanceIdle, Throttle: throttle},
+	}
+}
+
+func (r *rebalancer) Start() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.status.State == RebalanceRunning {
+		return ErrRebalanceRunning
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	r.status = RebalanceStatus{State: RebalanceRunning, StartedAt: &now, Throttle: r.status.Throttle}
+
+	go r.run(ctx, r.done)
+	return nil
+}
+
+func (r *rebalancer) Stop() {
+	r.mutex.Lock()
+	cancel, done := r.cancel, r.done
+	r.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (r *rebalancer) Status() RebalanceStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.status
+}
+
+func (r *rebalancer) SetThrottle(throttle RebalanceThrottle) error {
+	if throttle.ObjectsPerSecond < 0 || throttle.BytesPerSecond < 0 {
+		return fmt.Errorf("throttle limits must not be negative")
+	}
+
+	r.mutex.Lock()
+	r.status.Throttle = throttle
+	r.mutex.Unlock()
+
+	r.logger.Info("Rebalance throttle updated",
+		zap.Float64("objects_per_second", throttle.ObjectsPerSecond), zap.Int64("bytes_per_second", throttle.BytesPerSecond))
+	return nil
+}
+
+// update applies a change to the status under the lock
+func (r *rebalancer) update(change func(status *RebalanceStatus)) {
+	r.mutex.Lock()
+	change(&r.status)
+	r.mutex.Unlock()
+}
+
+func (r *rebalancer) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	r.logger.Info("Rebalance started")
+
+	err := r.rebalance(ctx)
+
+	now := time.Now()
+	r.update(func(status *RebalanceStatus) {
+		status.FinishedAt = &now
+		status.CurrentNode = ""
+		switch {
+		case errors.Is(err, context.Canceled):
+			status.State = RebalanceCancelled
+		case err != nil:
+			status.State = RebalanceFailed
+			status.LastError = err.Error()
+		default:
+			status.State = RebalanceCompleted
+		}
+	})
+
+	status := r.Status()
+	r.logger.Info("Rebalance finished",
+		zap.String("state", status.State),
+		zap.Int64("scanned", status.Scanned),
+		zap.Int64("moved", status.Moved),
+		zap.Int64("failed", status.Failed),
+		zap.Int64("bytes_moved", status.BytesMoved))
+}
+
+// rebalance scans every node of the current topology once
+func (r *rebalancer) rebalance(ctx context.Context) error {
+	s := r.service
+	s.clientsMutex.RLock()
+	nodes := append(s.nodes[:0:0], s.nodes...)
+	placement, replicationFactor := s.placement, s.replicationFactor()
+	clients := make(map[string]*minio.Client, len(s.clients))
+	for id, client := range s.clients {
+		clients[id] = client
+	}
+	s.clientsMutex.RUnlock()
+
+	replicas := make(map[string]replica, len(nodes))
+	for _, node := range nodes {
+		replicas[node.ID] = replica{node: node, client: clients[node.ID]}
+	}
+
+	for _, node := range nodes {
+		source := replicas[node.ID]
+		if source.client == nil {
+			r.logger.Warn("Skipping node without client during rebalance", zap.String("node_name", node.Name))
+			continue
+		}
+		r.update(func(status *RebalanceStatus) { status.CurrentNode = node.Name })
+
+		for obj := range source.client.ListObjects(ctx, bucketName, minio.ListObjectsOptions{Recursive: true}) {
+			if obj.Err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				r.logger.Error("Failed to list node during rebalance", zap.String("node_name", node.Name), zap.Error(obj.Err))
+				r.update(func(status *RebalanceStatus) { status.LastError = obj.Err.Error() })
+				break
+			}
+			r.update(func(status *RebalanceStatus) { status.Scanned++ })
+
+			// Objects stored on one of their owners are where they belong
+			owners := placement.Locate(obj.Key, replicationFactor)
+			if slices.Contains(owners, node.ID) {
+				continue
+			}
+			r.update(func(status *RebalanceStatus) { status.Misplaced++ })
+
+			targets := make([]replica, 0, len(owners))
+			for _, owner := range owners {
+				targets = append(targets, replicas[owner])
+			}
+
+			start := time.Now()
+			if err := r.migrate(ctx, source, targets, obj); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				r.logger.Error("Failed to migrate object", zap.String("object_id", obj.Key), zap.String("node_name", node.Name), zap.Error(err))
+				r.update(func(status *RebalanceStatus) {
+					status.Failed++
+					status.LastError = err.Error()
+				})
+				continue
+			}
+			r.update(func(status *RebalanceStatus) {
+				status.Moved++
+				status.BytesMoved += obj.Size
+			})
+
+			if err := r.throttle(ctx, obj.Size, time.Since(start)); err != nil {
+				return err
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// migrate makes sure every target holds the object before deleting it from the source
+func (r *rebalancer) migrate(ctx context.Context, source replica, targets []replica, obj minio.ObjectInfo) error {
+	for _, target := range targets {
+		if target.client == nil {
+			return fmt.Errorf("client for node %s not initialized", target.node.Name)
+		}
+
+		var targetETag string
+		existing, err := target.client.StatObject(ctx, bucketName, obj.Key, minio.StatObjectOptions{})
+		switch {
+		case err == nil && !existing.LastModified.Before(obj.LastModified):
+			// The target already holds this or a newer version, which must not be overwritten
+			continue
+		case err == nil:
+			targetETag = existing.ETag
+		case minio.ToErrorResponse(err).Code != "NoSuchKey":
+			return fmt.Errorf("failed to stat object on node %s: %w", target.node.Name, err)
+		}
+
+		err = copyBetweenNodes(ctx, source, target, obj.Key, obj.ETag, targetETag)
+		if err != nil && !errors.Is(err, errTargetChanged) {
+			return err
+		}
+	}
+
+	r.logger.Info("Migrated object", zap.String("object_id", obj.Key),
+		zap.String("from_node", source.node.Name), zap.Strings("to_nodes", replicaNames(targets)))
+
+	if err := source.client.RemoveObject(ctx, bucketName, obj.Key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete migrated object from node %s: %w", source.node.Name, err)
+	}
+	return nil
+}
+
+// throttle sleeps long enough to keep the migration within the configured limits
+func (r *rebalancer) throttle(ctx context.Context, size int64, took time.Duration) error {
+	limits := r.Status().Throttle
+
+	var wait time.Duration
+	if limits.ObjectsPerSecond > 0 {
+		wait = time.Duration(float64(time.Second) / limits.ObjectsPerSecond)
+	}
+	if limits.BytesPerSecond > 0 {
+		wait = max(wait, time.Duration(float64(size)/float64(limits.BytesPerSecond)*float64(time.Second)))
+	}
+	wait -= took
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// errTargetChanged is returned when the target of a copy was written to concurrently
+var errTargetChanged = errors.New("object changed on target node")
+
+// copyBetweenNodes copies the version of an object with the given ETag from one node to another
+// together with its metadata, and verifies the copy before returning. The copy only replaces the
+// target version with ETag targetETag, or creates the object when targetETag is empty, so a newer
+// version written concurrently is never overwritten.
+func copyBetweenNodes(ctx context.Context, source replica, target replica, objectID string, etag string, targetETag string) error {
+	getOpts := minio.GetObjectOptions{}
+	if err := getOpts.SetMatchETag(etag); err != nil {
+		return err
+	}
+
+	obj, err := source.client.GetObject(ctx, bucketName, objectID, getOpts)
+	if err != nil {
+		return fmt.Errorf("failed to read object from node %s: %w", source.node.Name, err)
+	}
+	defer obj.Close()
+
+	info, err := obj.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read object from node %s: %w", source.node.Name, err)
+	}
+
+	putOpts := putOptionsFromInfo(info)
+	if targetETag == "" {
+		putOpts.SetMatchETagExcept("*")
+	} else {
+		putOpts.SetMatchETag(targetETag)
+	}
+
+	hash := md5.New()
+	_, err = target.client.PutObject(ctx, bucketName, objectID, io.TeeReader(obj, hash), info.Size, putOpts)
+	if err != nil {
+		if code := minio.ToErrorResponse(err).Code; code == "PreconditionFailed" || code == "NoSuchKey" {
+			return errTargetChanged
+		}
+		return fmt.Errorf("failed to write object to node %s: %w", target.node.Name, err)
+	}
+
+	// Verify the copy before the caller relies on it
+	copied, err := target.client.StatObject(ctx, bucketName, objectID, minio.StatObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to verify object on node %s: %w", target.node.Name, err)
+	}
+	if copied.Size != info.Size {
+		return fmt.Errorf("copy of object on node %s has size %d, expected %d", target.node.Name, copied.Size, info.Size)
+	}
+	// Multipart ETags are not a digest of the content, so only plain MD5 ETags can be compared
+	if !strings.Contains(info.ETag, "-") {
+		if digest := hex.EncodeToString(hash.Sum(nil)); digest != info.ETag {
+			return fmt.Errorf("content read from node %s does not match its ETag", source.node.Name)
+		}
+		if !strings.Contains(copied.ETag, "-") && copied.ETag != info.ETag {
+			return fmt.Errorf("copy of object on node %s has ETag %s, expected %s", target.node.Name, copied.ETag, info.ETag)
+		}
+	}
+
+	return nil
+}
+
+// putOptionsFromInfo preserves the metadata of an object when it is copied
+func putOptionsFromInfo(info minio.ObjectInfo) minio.PutObjectOptions {
+	opts := minio.PutObjectOptions{
+		ContentType:  info.ContentType,
+		UserMetadata: info.UserMetadata,
+	}
+	if info.Metadata != nil {
+		opts.ContentDisposition = info.Metadata.Get("Content-Disposition")
+		opts.CacheControl = info.Metadata.Get("Cache-Control")
+	}
+	return opts
+}
@@ -0,0 +1,178 @@
+package objectStorage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRebalancerLifecycle(t *testing.T) {
+	service := newTestService(nil, DefaultConfig())
+	r := newRebalancer(service, RebalanceThrottle{ObjectsPerSecond: 10}, zap.NewNop())
+
+	status := r.Status()
+	assert.Equal(t, RebalanceIdle, status.State)
+	assert.Equal(t, RebalanceThrottle{ObjectsPerSecond: 10}, status.Throttle)
+
+	// Without nodes there is nothing to scan and the run completes straight away
+	assert.NoError(t, r.Start())
+	assert.Eventually(t, func() bool { return r.Status().State == RebalanceCompleted }, time.Second, 10*time.Millisecond)
+
+	status = r.Status()
+	assert.NotNil(t, status.StartedAt)
+	assert.NotNil(t, status.FinishedAt)
+	assert.Equal(t, int64(0), status.Scanned)
+
+	// The throttle survives restarts
+	assert.Equal(t, RebalanceThrottle{ObjectsPerSecond: 10}, status.Throttle)
+}
+
+func TestRebalancerStartWhileRunning(t *testing.T) {
+	r := newRebalancer(newTestService(nil, DefaultConfig()), RebalanceThrottle{}, zap.NewNop())
+	r.status.State = RebalanceRunning
+
+	assert.ErrorIs(t, r.Start(), ErrRebalanceRunning)
+}
+
+func TestRebalancerThrottle(t *testing.T) {
+	r := newRebalancer(newTestService(nil, DefaultConfig()), RebalanceThrottle{}, zap.NewNop())
+
+	assert.Error(t, r.SetThrottle(RebalanceThrottle{ObjectsPerSecond: -1}))
+	assert.NoError(t, r.SetThrottle(RebalanceThrottle{ObjectsPerSecond: 20, BytesPerSecond: 1000}))
+
+	// The larger of the two limits applies: 100 bytes at 1000 B/s is 100ms, more than 1/20s
+	start := time.Now()
+	assert.NoError(t, r.throttle(context.Background(), 100, 0))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// Time already spent on the object counts towards the limit
+	start = time.Now()
+	assert.NoError(t, r.throttle(context.Background(), 0, time.Second))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// Cancelling stops the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, r.throttle(ctx, 1000000, 0), context.Canceled)
+}
+
+func TestRebalancerMigrate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		prepare      func(fakes []*fakeS3, targets []replica)
+		expectedErr  string
+		sourceKept   bool
+		expectedCopy string
+	}{
+		{name: "Copied To Every Target", expectedCopy: "content"},
+		{
+			name: "Newer Version On Target Kept",
+			prepare: func(fakes []*fakeS3, targets []replica) {
+				putOnReplica(t, targets[0], "test123", "newer")
+				putOnReplica(t, targets[1], "test123", "newer")
+				for _, fake := range fakes[1:] {
+					object := fake.objects["test123"]
+					object.modified = object.modified.Add(time.Hour)
+					fake.objects["test123"] = object
+				}
+			},
+			expectedCopy: "newer",
+		},
+		{
+			name: "Content Not Matching ETag",
+			prepare: func(fakes []*fakeS3, targets []replica) {
+				// The source serves content that no longer matches the ETag it reports
+				object := fakes[0].objects["test123"]
+				object.data = []byte("corrupt")
+				fakes[0].objects["test123"] = object
+			},
+			expectedErr: `content read from node node-1 does not match its ETag`,
+			sourceKept:  true,
+		},
+		{
+			name: "Target Not Initialized",
+			prepare: func(fakes []*fakeS3, targets []replica) {
+				targets[1].client = nil
+			},
+			expectedErr: "client for node node-3 not initialized",
+			sourceKept:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, fakes, ctx := newTestCluster(t, 3, DefaultConfig())
+			r := newRebalancer(service, RebalanceThrottle{}, zap.NewNop())
+			replicas := nodeReplicas(service)
+			source, targets := replicas[0], replicas[1:]
+			_, err := source.client.PutObject(ctx, bucketName, "test123", strings.NewReader("content"), 7, minio.PutObjectOptions{ContentType: "text/plain", UserMetadata: map[string]string{"Owner": "sync"}})
+			require.NoError(t, err)
+			obj, err := source.client.StatObject(ctx, bucketName, "test123", minio.StatObjectOptions{})
+			require.NoError(t, err)
+			if tc.prepare != nil {
+				tc.prepare(fakes, targets)
+			}
+
+			err = r.migrate(ctx, source, targets, obj)
+
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			_, err = source.client.StatObject(ctx, bucketName, "test123", minio.StatObjectOptions{})
+			assert.Equal(t, tc.sourceKept, err == nil)
+			if tc.expectedCopy == "" {
+				return
+			}
+			for _, target := range targets {
+				assert.True(t, replicaHolds(t, target, "test123", tc.expectedCopy), target.node.Name)
+			}
+			// The metadata moves along with the content
+			if tc.expectedCopy == "content" {
+				info, err := targets[0].client.StatObject(ctx, bucketName, "test123", minio.StatObjectOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, "text/plain", info.ContentType)
+				assert.Equal(t, "sync", info.UserMetadata["Owner"])
+			}
+		})
+	}
+}
+
+func TestRebalancerMovesMisplacedObjects(t *testing.T) {
+	service, _, ctx := newTestCluster(t, 3, DefaultConfig())
+	replicas := nodeReplicas(service)
+
+	// Every node holds a copy of every object, two of which are misplaced
+	var objectIDs []string
+	for i := 0; i < 5; i++ {
+		objectID := fmt.Sprintf("object%d", i)
+		objectIDs = append(objectIDs, objectID)
+		for _, r := range replicas {
+			putOnReplica(t, r, objectID, "content")
+		}
+	}
+
+	r := newRebalancer(service, RebalanceThrottle{}, zap.NewNop())
+	require.NoError(t, r.rebalance(ctx))
+
+	status := r.Status()
+	assert.Equal(t, int64(15), status.Scanned)
+	assert.Equal(t, int64(10), status.Misplaced)
+	assert.Equal(t, int64(10), status.Moved)
+	assert.Zero(t, status.Failed)
+	for _, objectID := range objectIDs {
+		owners, err := service.getReplicasForID(objectID)
+		require.NoError(t, err)
+		for _, r := range replicas {
+			assert.Equal(t, r.node.ID == owners[0].node.ID, replicaHolds(t, r, objectID, "content"), objectID)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package objectStorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/minio/minio-go/v7"
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"go.uber.org/zap"
+)
+
+// replica is a node holding a copy of an object. The client is nil while the node is not initialized.
+type replica struct {
+	node    docker.MinioNode
+	client  *minio.Client
+	breaker *circuitBreaker
+}
+
+// replicaResult is the outcome of an operation on a single replica
+type replicaResult struct {
+	replica replica
+	info    minio.ObjectInfo
+	err     error
+}
+
+// replicaWriter feeds the upload of a single replica
+type replicaWriter struct {
+	pipe   *io.PipeWriter
+	failed bool
+}
+
+// fanoutWriter copies every write to all replicas whose upload is still running.
+// A failing replica is dropped so it does not hold back the others.
+type fanoutWriter struct {
+	writers []*replicaWriter
+}
+
+func (f *fanoutWriter) Write(p []byte) (int, error) {
+	alive := 0
+	for _, w := range f.writers {
+		if w.failed {
+			continue
+		}
+		if _, err := w.pipe.Write(p); err != nil {
+			w.failed = true
+			continue
+		}
+		alive++
+	}
+	if alive == 0 {
+		return 0, errors.New("all replica uploads failed")
+	}
+	return len(p), nil
+}
+
+// putReplicated streams data to every replica at once and succeeds once writeQuorum of them
+// have stored it. Replicas that failed are left for the rebalancer or a later write to repair.
+func (s *minioStorageService) putReplicated(ctx context.Context, replicas []replica, objectID string, data io.Reader, size int64, opts minio.PutObjectOptions, writeQuorum int) error {
+	results := make(chan replicaResult, len(replicas))
+	var writers []*replicaWriter
+
+	for _, r := range replicas {
+		if r.client == nil {
+			results <- replicaResult{replica: r, err: fmt.Errorf("client for node %s not initialized", r.node.Name)}
+			continue
+		}
+
+		// Nodes behind an open circuit breaker do not get a copy of the data
+		if err := r.breaker.allow(); err != nil {
+			results <- replicaResult{replica: r, err: err}
+			continue
+		}
+
+		pr, pw := io.Pipe()
+		writers = append(writers, &replicaWriter{pipe: pw})
+		go func(r replica) {
+			_, err := r.client.PutObject(ctx, bucketName, objectID, pr, size, opts)
+			r.breaker.record(err)
+			// Unblock the fan-out if the upload stopped reading early
+			pr.CloseWithError(err)
+			results <- replicaResult{replica: r, err: err}
+		}(r)
+	}
+
+	if len(writers) > 0 {
+		_, copyErr := io.Copy(&fanoutWriter{writers: writers}, data)
+		for _, w := range writers {
+			w.pipe.CloseWithError(copyErr)
+		}
+	}
+
+	var acknowledged, preconditionFailures, tooLarge int
+	var firstErr error
+	for range replicas {
+		result := <-results
+		if result.err == nil {
+			acknowledged++
+			continue
+		}
+
+		switch minio.ToErrorResponse(result.err).Code {
+		case "PreconditionFailed", "NoSuchKey":
+			// NoSuchKey means an If-Match write found no object to replace
+			preconditionFailures++
+		case "EntityTooLarge":
+			tooLarge++
+		default:
+			s.logger.Warn("Failed to store object on replica",
+				zap.String("object_id", objectID), zap.String("node_name", result.replica.node.Name), zap.Error(result.err))
+		}
+		firstErr = preferUnavailable(firstErr, result.err)
+	}
+
+	if acknowledged >= writeQuorum {
+		if acknowledged < len(replicas) {
+			s.logger.Warn("Object is under-replicated",
+				zap.String("object_id", objectID), zap.Int("replicas", acknowledged), zap.Int("replication_factor", len(replicas)))
+		}
+		return nil
+	}
+	if preconditionFailures > 0 {
+		return ErrPreconditionFailed
+	}
+	if tooLarge > 0 {
+		return NewError(ErrTooLarge, "", firstErr)
+	}
+	return NewError(ErrUnavailable, fmt.Sprintf("failed to store object: write quorum not reached (%d/%d)", acknowledged, writeQuorum), firstErr)
+}
+
+// missingQuorum reports whether enough of the replicas lack an object to treat it as missing: so
+// many that readQuorum of them can no longer agree on a version, and at least a majority. A replica
+// that lags behind therefore never hides an object the replicas still answering may hold.
+func missingQuorum(missing int, replicas int, readQuorum int) bool {
+	return missing > 0 && missing > replicas-readQuorum && missing > replicas/2
+}
+
+// statQuorum asks all replicas for the object's metadata and returns as soon as readQuorum of them
+// agree on the same version, together with the replicas holding that version. The object is
+// missing once too many replicas report it missing for the read quorum to be met, see missingQuorum.
+func (s *minioStorageService) statQuorum(ctx context.Context, replicas []replica, objectID string, readQuorum int) (minio.ObjectInfo, []replica, error) {
+	// Cancelling the context abandons the replicas that have not answered yet
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan replicaResult, len(replicas))
+	for _, r := range replicas {
+		if r.client == nil {
+			results <- replicaResult{replica: r, err: fmt.Errorf("client for node %s not initialized", r.node.Name)}
+			continue
+		}
+		go func(r replica) {
+			var info minio.ObjectInfo
+			err := r.call(func() (err error) {
+				info, err = r.client.StatObject(ctx, bucketName, objectID, minio.StatObjectOptions{})
+				return err
+			})
+			results <- replicaResult{replica: r, info: info, err: err}
+		}(r)
+	}
+
+	versions := make(map[string][]replica)
+	var notFound int
+	var firstErr error
+	for range replicas {
+		result := <-results
+		if result.err != nil {
+			if minio.ToErrorResponse(result.err).Code == "NoSuchKey" {
+				notFound++
+				if missingQuorum(notFound, len(replicas), readQuorum) {
+					return minio.ObjectInfo{}, nil, ErrNotFound
+				}
+			} else {
+				firstErr = preferUnavailable(firstErr, result.err)
+			}
+			continue
+		}
+
+		etag := result.info.ETag
+		versions[etag] = append(versions[etag], result.replica)
+		if len(versions[etag]) >= readQuorum {
+			return result.info, versions[etag], nil
+		}
+	}
+
+	if firstErr == nil {
+		firstErr = errors.New("replicas disagree")
+	}
+	return minio.ObjectInfo{}, nil, NewError(ErrUnavailable, "failed to stat object: read quorum not reached", firstErr)
+}
+
+// deleteReplicated removes the object from every replica and succeeds once writeQuorum of them did
+func (s *minioStorageService) deleteReplicated(ctx context.Context, replicas []replica, objectID string, writeQuorum int) error {
+	var wg sync.WaitGroup
+	results := make(chan replicaResult, len(replicas))
+	for _, r := range replicas {
+		if r.client == nil {
+			results <- replicaResult{replica: r, err: fmt.Errorf("client for node %s not initialized", r.node.Name)}
+			continue
+		}
+		wg.Add(1)
+		go func(r replica) {
+			defer wg.Done()
+			err := r.call(func() error {
+				return r.client.RemoveObject(ctx, bucketName, objectID, minio.RemoveObjectOptions{})
+			})
+			results <- replicaResult{replica: r, err: err}
+		}(r)
+	}
+	wg.Wait()
+	close(results)
+
+	var acknowledged int
+	var firstErr error
+	for result := range results {
+		if result.err == nil {
+			acknowledged++
+			continue
+		}
+		s.logger.Warn("Failed to delete object from replica",
+			zap.String("object_id", objectID), zap.String("node_name", result.replica.node.Name), zap.Error(result.err))
+		firstErr = preferUnavailable(firstErr, result.err)
+	}
+
+	if acknowledged < writeQuorum {
+		return NewError(ErrUnavailable, fmt.Sprintf("failed to delete object: write quorum not reached (%d/%d)", acknowledged, writeQuorum), firstErr)
+	}
+	return nil
+}
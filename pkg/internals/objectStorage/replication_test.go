@@ -0,0 +1,377 @@
+package objectStorage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestNodes(count int) []docker.MinioNode {
+	nodes := make([]docker.MinioNode, 0, count)
+	for i := 1; i <= count; i++ {
+		nodes = append(nodes, docker.MinioNode{ID: fmt.Sprintf("id-%d", i), Name: fmt.Sprintf("node-%d", i)})
+	}
+	return nodes
+}
+
+// newTestService builds a service for the nodes without connecting to them
+func newTestService(nodes []docker.MinioNode, config Config) *minioStorageService {
+	service := &minioStorageService{
+		nodes:        nodes,
+		clients:      make(map[string]*minio.Client),
+		clientErrors: make(map[string]error),
+		breakers:     make(map[string]*circuitBreaker),
+		connecting:   make(map[string]*connectAttempt),
+		placement:    newPlacement(nodes, config),
+		config:       config,
+		logger:       zap.NewNop(),
+	}
+	service.health = newHealthChecker(service, config, zap.NewNop())
+	return service
+}
+
+func TestGetReplicasForID(t *testing.T) {
+	testCases := []struct {
+		name             string
+		nodes            int
+		replication      int
+		expectedReplicas int
+	}{
+		{name: "Single Replica", nodes: 3, replication: 1, expectedReplicas: 1},
+		{name: "Three Replicas", nodes: 3, replication: 3, expectedReplicas: 3},
+		{name: "Capped By Node Count", nodes: 2, replication: 3, expectedReplicas: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.ReplicationFactor = tc.replication
+			service := newTestService(newTestNodes(tc.nodes), config)
+			single := newTestService(newTestNodes(tc.nodes), DefaultConfig())
+
+			for i := 0; i < 100; i++ {
+				objectID := fmt.Sprintf("object%d", i)
+				replicas, err := service.getReplicasForID(objectID)
+				assert.NoError(t, err)
+				assert.Len(t, replicas, tc.expectedReplicas)
+
+				// Replicas are distinct and the primary is the single-copy placement
+				seen := map[string]bool{}
+				for _, r := range replicas {
+					assert.False(t, seen[r.node.ID])
+					seen[r.node.ID] = true
+				}
+				primary, _ := single.getReplicasForID(objectID)
+				assert.Equal(t, primary[0].node, replicas[0].node)
+			}
+		})
+	}
+
+	_, err := newTestService(nil, DefaultConfig()).getReplicasForID("object")
+	assert.EqualError(t, err, "no storage nodes available")
+}
+
+func TestFanoutWriter(t *testing.T) {
+	healthyReader, healthyWriter := io.Pipe()
+	failedReader, failedWriter := io.Pipe()
+	failedReader.CloseWithError(fmt.Errorf("node down"))
+
+	received := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(healthyReader)
+		received <- data
+	}()
+
+	fanout := &fanoutWriter{writers: []*replicaWriter{{pipe: healthyWriter}, {pipe: failedWriter}}}
+	_, err := io.Copy(fanout, bytes.NewReader([]byte("replicated content")))
+	assert.NoError(t, err)
+	healthyWriter.Close()
+
+	assert.Equal(t, "replicated content", string(<-received))
+	assert.False(t, fanout.writers[0].failed)
+	assert.True(t, fanout.writers[1].failed)
+
+	// Once every replica has failed the copy is aborted
+	_, err = fanout.Write([]byte("more"))
+	assert.EqualError(t, err, "all replica uploads failed")
+}
+
+// newTestCluster builds a service whose nodes are fake S3 servers, which are returned in node order
+func newTestCluster(t *testing.T, count int, config Config) (*minioStorageService, []*fakeS3, *gin.Context) {
+	nodes := newTestNodes(count)
+	service := newTestService(nodes, config)
+	fakes := make([]*fakeS3, 0, count)
+	for _, node := range nodes {
+		fake := newFakeS3(bucketName)
+		fakes = append(fakes, fake)
+		server := httptest.NewServer(fake)
+		t.Cleanup(server.Close)
+		client, err := minio.New(server.Listener.Addr().String(), &minio.Options{Creds: credentials.NewStaticV4("access", "secret", ""), Region: "us-east-1"})
+		require.NoError(t, err)
+		service.clients[node.ID] = client
+	}
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	return service, fakes, ctx
+}
+
+// nodeReplicas returns a replica for every node of the service, in node order
+func nodeReplicas(service *minioStorageService) []replica {
+	replicas := make([]replica, 0, len(service.nodes))
+	for _, node := range service.nodes {
+		replicas = append(replicas, service.replicaOf(node))
+	}
+	return replicas
+}
+
+// putOnReplica stores content on a single replica, bypassing replication
+func putOnReplica(t *testing.T, r replica, objectID string, content string) {
+	_, err := r.client.PutObject(context.Background(), bucketName, objectID, strings.NewReader(content), int64(len(content)), minio.PutObjectOptions{})
+	require.NoError(t, err)
+}
+
+// replicaHolds reports whether a single replica stores the object with the given content
+func replicaHolds(t *testing.T, r replica, objectID string, content string) bool {
+	obj, _, _, err := minio.Core{Client: r.client}.GetObject(context.Background(), bucketName, objectID, minio.GetObjectOptions{})
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return false
+	}
+	require.NoError(t, err)
+	defer obj.Close()
+	data, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	return string(data) == content
+}
+
+func TestGetObjectRange(t *testing.T) {
+	service, _, ctx := newTestCluster(t, 1, DefaultConfig())
+	assert.NoError(t, putString(ctx, service, "test123", "This is a test object", PutOptions{}))
+
+	// Only the requested bytes are sent by the node
+	obj, info, err := service.GetObject(ctx, "test123", GetOptions{Range: &ByteRange{Start: 10, End: 13}})
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(obj)
+	obj.Close()
+	assert.Equal(t, "test", string(data))
+	assert.Equal(t, int64(4), info.Size)
+
+	// A range the object no longer covers is reported as such
+	_, _, err = service.GetObject(ctx, "test123", GetOptions{Range: &ByteRange{Start: 100, End: 200}})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	_, _, err = service.GetObject(ctx, "missing", GetOptions{})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPutReplicated(t *testing.T) {
+	testCases := []struct {
+		name          string
+		writeQuorum   int
+		uninitialized int
+		existing      int
+		ifNoneMatch   bool
+		expectedErr   error
+		expectedHeld  int
+	}{
+		{name: "All Replicas", writeQuorum: 3, expectedHeld: 3},
+		{name: "Quorum Without A Replica", writeQuorum: 2, uninitialized: 1, expectedHeld: 2},
+		{name: "Quorum Missed", writeQuorum: 2, uninitialized: 2, expectedErr: ErrUnavailable, expectedHeld: 1},
+		{name: "Precondition Failed Everywhere", writeQuorum: 2, existing: 3, ifNoneMatch: true, expectedErr: ErrPreconditionFailed},
+		{name: "Precondition Failed On A Replica", writeQuorum: 2, existing: 1, ifNoneMatch: true, expectedHeld: 2},
+		// The replicas that accepted the write keep it
+		{name: "Precondition Failed Below Quorum", writeQuorum: 3, existing: 1, ifNoneMatch: true, expectedErr: ErrPreconditionFailed, expectedHeld: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, _, ctx := newTestCluster(t, 3, DefaultConfig())
+			replicas := nodeReplicas(service)
+			for i := 0; i < tc.existing; i++ {
+				putOnReplica(t, replicas[i], "test123", "old")
+			}
+			writing := append([]replica{}, replicas...)
+			for i := 0; i < tc.uninitialized; i++ {
+				writing[len(writing)-1-i].client = nil
+			}
+
+			opts := minio.PutObjectOptions{}
+			if tc.ifNoneMatch {
+				opts.SetMatchETagExcept("*")
+			}
+			err := service.putReplicated(ctx, writing, "test123", strings.NewReader("new"), 3, opts, tc.writeQuorum)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			held := 0
+			for _, r := range replicas {
+				if replicaHolds(t, r, "test123", "new") {
+					held++
+				}
+			}
+			assert.Equal(t, tc.expectedHeld, held)
+		})
+	}
+}
+
+func TestStatQuorum(t *testing.T) {
+	testCases := []struct {
+		name          string
+		contents      []string
+		readQuorum    int
+		uninitialized bool
+		expectedErr   error
+		expectedETag  string
+		expectedAgree int
+	}{
+		{name: "All Agree", contents: []string{"v1", "v1", "v1"}, readQuorum: 2, expectedETag: "v1", expectedAgree: 2},
+		{name: "Single Replica Missing", contents: []string{"", "v1", "v1"}, readQuorum: 2, expectedETag: "v1", expectedAgree: 2},
+		// Without a read quorum a lagging replica must not hide the object
+		{name: "Lagging Replica Without Quorum", contents: []string{"", "", "v1"}, readQuorum: 1, expectedETag: "v1", expectedAgree: 1},
+		{name: "Majority Missing", contents: []string{"", "", "v1"}, readQuorum: 2, expectedErr: ErrNotFound},
+		{name: "Missing Everywhere", contents: []string{"", "", ""}, readQuorum: 1, expectedErr: ErrNotFound},
+		{name: "Replicas Disagree", contents: []string{"v1", "v2", ""}, readQuorum: 2, expectedErr: ErrUnavailable},
+		{name: "Quorum Without A Replica", contents: []string{"v1", "v1", "v1"}, readQuorum: 2, uninitialized: true, expectedETag: "v1", expectedAgree: 2},
+		{name: "Missing On Too Few Replicas", contents: []string{"", "v1", "v1"}, readQuorum: 3, uninitialized: true, expectedErr: ErrUnavailable},
+		{name: "Missing On A Minority", contents: []string{"", "v1", "v1"}, readQuorum: 3, expectedErr: ErrUnavailable},
+		{name: "Lagging Replicas Without Answer", contents: []string{"", "", "v1"}, readQuorum: 1, uninitialized: true, expectedErr: ErrUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, _, ctx := newTestCluster(t, 3, DefaultConfig())
+			replicas := nodeReplicas(service)
+			etags := map[string]string{}
+			for i, content := range tc.contents {
+				if content != "" {
+					putOnReplica(t, replicas[i], "test123", content)
+					info, err := replicas[i].client.StatObject(ctx, bucketName, "test123", minio.StatObjectOptions{})
+					require.NoError(t, err)
+					etags[info.ETag] = content
+				}
+			}
+			if tc.uninitialized {
+				replicas[len(replicas)-1].client = nil
+			}
+
+			info, agreeing, err := service.statQuorum(ctx, replicas, "test123", tc.readQuorum)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedETag, etags[info.ETag])
+			assert.Len(t, agreeing, tc.expectedAgree)
+		})
+	}
+}
+
+func TestDeleteReplicated(t *testing.T) {
+	testCases := []struct {
+		name          string
+		writeQuorum   int
+		uninitialized int
+		expectedErr   error
+	}{
+		{name: "All Replicas", writeQuorum: 3},
+		{name: "Quorum Without A Replica", writeQuorum: 2, uninitialized: 1},
+		{name: "Quorum Missed", writeQuorum: 2, uninitialized: 2, expectedErr: ErrUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, _, ctx := newTestCluster(t, 3, DefaultConfig())
+			replicas := nodeReplicas(service)
+			for _, r := range replicas {
+				putOnReplica(t, r, "test123", "content")
+			}
+			deleting := append([]replica{}, replicas...)
+			for i := 0; i < tc.uninitialized; i++ {
+				deleting[len(deleting)-1-i].client = nil
+			}
+
+			err := service.deleteReplicated(ctx, deleting, "test123", tc.writeQuorum)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			// The object is gone from every replica that was reached
+			for i, r := range replicas {
+				assert.Equal(t, i >= len(replicas)-tc.uninitialized, replicaHolds(t, r, "test123", "content"), r.node.Name)
+			}
+		})
+	}
+}
+
+func TestGetObjectLaggingReplica(t *testing.T) {
+	config := DefaultConfig()
+	config.ReplicationFactor = 3
+	service, _, ctx := newTestCluster(t, 3, config)
+	replicas, err := service.getReplicasForID("test123")
+	require.NoError(t, err)
+
+	// Only the last replica has the object, the others have not received it yet
+	putOnReplica(t, replicas[2], "test123", "content")
+
+	// The replicas answer in any order, the lagging ones must never hide the object
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, "content", readString(t, ctx, service, "test123", GetOptions{}))
+		assert.Equal(t, "tent", readString(t, ctx, service, "test123", GetOptions{Range: &ByteRange{Start: 3, End: 6}}))
+		info, err := service.StatObject(ctx, "test123")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), info.Size)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, DefaultConfig().Validate())
+	replicated := DefaultConfig()
+	replicated.ReplicationFactor, replicated.WriteQuorum, replicated.ReadQuorum = 3, 2, 2
+	replicated.Placement, replicated.VirtualNodes = "rendezvous", 1
+	assert.NoError(t, replicated.Validate())
+	assert.Error(t, Config{ReplicationFactor: 0, WriteQuorum: 1, ReadQuorum: 1, Placement: "ring", VirtualNodes: 1}.Validate())
+	assert.Error(t, Config{ReplicationFactor: 2, WriteQuorum: 3, ReadQuorum: 1, Placement: "ring", VirtualNodes: 1}.Validate())
+	assert.Error(t, Config{ReplicationFactor: 2, WriteQuorum: 1, ReadQuorum: 0, Placement: "ring", VirtualNodes: 1}.Validate())
+	assert.Error(t, Config{ReplicationFactor: 1, WriteQuorum: 1, ReadQuorum: 1, Placement: "ring", VirtualNodes: 0}.Validate())
+	assert.Error(t, Config{ReplicationFactor: 1, WriteQuorum: 1, ReadQuorum: 1, Placement: "modulo", VirtualNodes: 1}.Validate())
+
+	unhealthy := DefaultConfig()
+	unhealthy.UnhealthyThreshold = 0
+	assert.EqualError(t, unhealthy.Validate(), "health check thresholds must be at least 1")
+
+	memory := DefaultConfig()
+	memory.MemoryMaxBytes = -1
+	assert.EqualError(t, memory.Validate(), "memory limit must not be negative")
+
+	health := DefaultConfig()
+	health.HealthCheckTimeout = 0
+	assert.EqualError(t, health.Validate(), "health check timeout must be positive when health checks are enabled")
+	health.HealthCheckInterval = 0
+	assert.NoError(t, health.Validate())
+
+	rotation := DefaultConfig()
+	rotation.EncryptionRotate = true
+	assert.EqualError(t, rotation.Validate(), "key rotation needs a key file")
+
+	fileConfig := DefaultConfig()
+	fileConfig.Discovery = DiscoveryFile
+	assert.EqualError(t, fileConfig.Validate(), "file discovery needs a nodes file")
+	fileConfig.NodesFile = "nodes.yaml"
+	assert.NoError(t, fileConfig.Validate())
+}
@@ -0,0 +1,309 @@
+package objectStorage
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	utils "github.com/singhmeghna79/homework-object-storage/pkg"
+	"go.uber.org/zap"
+)
+
+// S3Config describes the bucket of the s3 storage type
+type S3Config struct {
+	// Endpoint is the host and optional port of the S3 API, without a scheme
+	Endpoint string
+	// Region of the bucket, left empty it is looked up from the service
+	Region string
+	// Bucket holding the objects, it has to exist already
+	Bucket string
+	// AccessKey and SecretKey sign the requests. Left empty they are read from the
+	// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables.
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket in the path instead of the host name
+	PathStyle bool
+	// TLS connects with HTTPS
+	TLS bool
+	// CAFile is a PEM file with certificates trusted in addition to the system ones
+	CAFile string
+	// InsecureSkipVerify accepts any server certificate, for testing only
+	InsecureSkipVerify bool
+}
+
+// DefaultS3Config returns the S3 settings used when no flags are given
+func DefaultS3Config() S3Config {
+	return S3Config{TLS: true}
+}
+
+// Validate checks that the bucket can be addressed
+func (c S3Config) Validate() error {
+	if c.Endpoint == "" || c.Bucket == "" {
+		return fmt.Errorf("s3 storage needs an endpoint and a bucket")
+	}
+	if strings.Contains(c.Endpoint, "://") {
+		return fmt.Errorf("s3 endpoint %q must not contain a scheme, TLS is selected separately", c.Endpoint)
+	}
+	return nil
+}
+
+// s3StorageService stores objects in a bucket of an S3 compatible service, addressed
+// through an explicit endpoint instead of discovered nodes
+type s3StorageService struct {
+	client *minio.Client
+	config S3Config
+	// timeout bounds the bucket check of the readiness probe
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewS3StorageService creates the client for the configured bucket. No request is sent
+// until the storage is used, whether the bucket can be reached is reported by Readiness.
+func NewS3StorageService(config Config, logger *zap.Logger) (*s3StorageService, error) {
+	if err := config.S3.Validate(); err != nil {
+		return nil, err
+	}
+
+	transport, err := newS3Transport(config.S3)
+	if err != nil {
+		return nil, err
+	}
+	return newS3StorageService(config, transport, logger)
+}
+
+// newS3StorageService creates the service sending its requests through transport
+func newS3StorageService(config Config, transport http.RoundTripper, logger *zap.Logger) (*s3StorageService, error) {
+	s3Config := config.S3
+
+	creds := credentials.NewStaticV4(s3Config.AccessKey, s3Config.SecretKey, "")
+	if s3Config.AccessKey == "" {
+		creds = credentials.NewEnvAWS()
+	}
+
+	lookup := minio.BucketLookupDNS
+	if s3Config.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(s3Config.Endpoint, &minio.Options{
+		Creds:        creds,
+		Secure:       s3Config.TLS,
+		Region:       s3Config.Region,
+		BucketLookup: lookup,
+		Transport:    transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	logger.Info("Storing objects in S3 bucket",
+		zap.String("endpoint", s3Config.Endpoint), zap.String("bucket", s3Config.Bucket),
+		zap.String("region", s3Config.Region), zap.Bool("path_style", s3Config.PathStyle), zap.Bool("tls", s3Config.TLS))
+
+	timeout := config.HealthCheckTimeout
+	if timeout <= 0 {
+		timeout = DefaultConfig().HealthCheckTimeout
+	}
+	return &s3StorageService{client: client, config: s3Config, timeout: timeout, logger: logger}, nil
+}
+
+// newS3Transport returns the HTTP transport trusting the configured certificates
+func newS3Transport(config S3Config) (*http.Transport, error) {
+	transport, err := minio.DefaultTransport(config.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 transport: %w", err)
+	}
+	if !config.TLS {
+		return transport, nil
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	transport.TLSClientConfig.InsecureSkipVerify = config.InsecureSkipVerify
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read S3 CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("S3 CA file %s holds no PEM certificates", config.CAFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	return transport, nil
+}
+
+// s3Error classifies an error returned by the S3 service
+func s3Error(err error, message string) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey":
+		return ErrNotFound
+	case "PreconditionFailed":
+		return ErrPreconditionFailed
+	case "EntityTooLarge":
+		return NewError(ErrTooLarge, "", err)
+	case "InvalidRange":
+		return NewError(ErrInvalidRange, "", err)
+	}
+	if isNodeFailure(err) {
+		return NewError(ErrUnavailable, message, err)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// PutObject uploads the object. The write conditions are sent along and checked by the service
+// against the single copy in the bucket.
+func (s *s3StorageService) PutObject(ctx *gin.Context, objectID string, data io.Reader, size int64, opts PutOptions) error {
+	logger := utils.GetLogger(ctx)
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
+	}
+
+	putOpts := minio.PutObjectOptions{
+		ContentType:        opts.ContentType,
+		ContentDisposition: opts.ContentDisposition,
+		CacheControl:       opts.CacheControl,
+		UserMetadata:       opts.UserMetadata,
+	}
+	if opts.IfMatch != "" {
+		putOpts.SetMatchETag(opts.IfMatch)
+	}
+	if opts.IfNoneMatch != "" {
+		putOpts.SetMatchETagExcept(opts.IfNoneMatch)
+	}
+
+	_, err := s.client.PutObject(ctx, s.config.Bucket, objectID, data, size, putOpts)
+	if err != nil {
+		// NoSuchKey means an If-Match write found no object to replace
+		if opts.IfMatch != "" && minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return ErrPreconditionFailed
+		}
+		return s3Error(err, "failed to store object")
+	}
+
+	logger.Info("Stored object in S3 bucket", zap.String("object_id", objectID), zap.String("bucket", s.config.Bucket))
+	return nil
+}
+
+// GetObject retrieves the object, or the requested range of it
+func (s *s3StorageService) GetObject(ctx *gin.Context, objectID string, opts GetOptions) (io.ReadCloser, ObjectInfo, error) {
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	getOpts := minio.GetObjectOptions{}
+	if opts.Range != nil {
+		if err := getOpts.SetRange(opts.Range.Start, opts.Range.End); err != nil {
+			return nil, ObjectInfo{}, NewError(ErrInvalidRange, "invalid range", err)
+		}
+	}
+
+	// The core client sends a single request, the object reader would drop the range once its stats are read
+	obj, info, _, err := minio.Core{Client: s.client}.GetObject(ctx, s.config.Bucket, objectID, getOpts)
+	if err != nil {
+		return nil, ObjectInfo{}, s3Error(err, "failed to get object")
+	}
+
+	return obj, toObjectInfo(info), nil
+}
+
+// StatObject returns the metadata of an object without retrieving its content
+func (s *s3StorageService) StatObject(ctx *gin.Context, objectID string) (ObjectInfo, error) {
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info, err := s.client.StatObject(ctx, s.config.Bucket, objectID, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, s3Error(err, "failed to stat object")
+	}
+	return toObjectInfo(info), nil
+}
+
+// DeleteObject removes an object. S3 reports success for keys that are already gone.
+func (s *s3StorageService) DeleteObject(ctx *gin.Context, objectID string) error {
+	logger := utils.GetLogger(ctx)
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
+	}
+
+	if err := s.client.RemoveObject(ctx, s.config.Bucket, objectID, minio.RemoveObjectOptions{}); err != nil {
+		return s3Error(err, "failed to delete object")
+	}
+
+	logger.Info("Deleted object from S3 bucket", zap.String("object_id", objectID), zap.String("bucket", s.config.Bucket))
+	return nil
+}
+
+// ListObjects returns a page of the objects in the bucket. Keys that are not valid object IDs,
+// written to the bucket by other applications, are skipped.
+func (s *s3StorageService) ListObjects(ctx *gin.Context, opts ListOptions) (*ListResult, error) {
+	limit, startAfter, err := normalizeListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cancelling the context stops the listing once the page is full
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := &ListResult{Objects: []ObjectInfo{}}
+	for object := range s.client.ListObjects(listCtx, s.config.Bucket, minio.ListObjectsOptions{
+		Prefix:     opts.Prefix,
+		StartAfter: startAfter,
+		Recursive:  true,
+	}) {
+		if object.Err != nil {
+			return nil, s3Error(object.Err, "failed to list objects")
+		}
+		if validateObjectID(object.Key) != nil {
+			continue
+		}
+		if len(result.Objects) == limit {
+			result.IsTruncated = true
+			result.NextCursor = encodeListCursor(result.Objects[limit-1].ID)
+			break
+		}
+		result.Objects = append(result.Objects, toObjectInfo(object))
+	}
+
+	return result, nil
+}
+
+// Readiness reports whether the bucket can be reached
+func (s *s3StorageService) Readiness() Readiness {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	readiness := Readiness{RequiredNodes: 1}
+	exists, err := s.client.BucketExists(ctx, s.config.Bucket)
+	switch {
+	case err != nil:
+		readiness.FailingNodes = []FailingNode{{Name: s.config.Endpoint, Reason: fmt.Sprintf("failed to check if bucket exists: %v", err)}}
+	case !exists:
+		readiness.FailingNodes = []FailingNode{{Name: s.config.Endpoint, Reason: fmt.Sprintf("bucket %s does not exist", s.config.Bucket)}}
+	default:
+		readiness.Ready, readiness.ReadyNodes = true, 1
+	}
+	return readiness
+}
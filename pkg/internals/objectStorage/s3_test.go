@@ -0,0 +1,419 @@
+package objectStorage
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeS3 is a minimal S3 server holding a single bucket in memory. It understands path-style and
+// virtual-host addressing and ignores request signatures.
+type fakeS3 struct {
+	bucket string
+
+	mutex   sync.Mutex
+	objects map[string]fakeS3Object
+	hosts   []string
+}
+
+type fakeS3Object struct {
+	data     []byte
+	etag     string
+	modified time.Time
+	header   http.Header
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{bucket: bucket, objects: make(map[string]fakeS3Object)}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.hosts = append(f.hosts, r.Host)
+
+	// Virtual-host requests name the bucket in the host, path-style ones in the path
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if !strings.HasPrefix(r.Host, f.bucket+".") {
+		bucket, key, _ := strings.Cut(path, "/")
+		if bucket != f.bucket {
+			writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+			return
+		}
+		path = key
+	}
+
+	switch {
+	case path == "" && r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case path == "" && r.Method == http.MethodGet && r.URL.Query().Has("location"):
+		fmt.Fprint(w, `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LocationConstraint>`)
+	case path == "" && r.Method == http.MethodGet:
+		f.list(w, r)
+	case r.Method == http.MethodPut:
+		f.put(w, r, path)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		f.get(w, r, path)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) put(w http.ResponseWriter, r *http.Request, key string) {
+	data, err := readS3Body(r)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	current, exists := f.objects[key]
+	if match := r.Header.Get("If-Match"); match != "" {
+		if !exists {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		if match != "*" && strings.Trim(match, `"`) != current.etag {
+			writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+	}
+	if noneMatch := r.Header.Get("If-None-Match"); noneMatch != "" && exists &&
+		(noneMatch == "*" || strings.Trim(noneMatch, `"`) == current.etag) {
+		writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+
+	header := http.Header{}
+	for name, values := range r.Header {
+		if name == "Content-Type" || name == "Content-Disposition" || name == "Cache-Control" || strings.HasPrefix(name, "X-Amz-Meta-") {
+			header[name] = values
+		}
+	}
+	sum := md5.Sum(data)
+	object := fakeS3Object{data: data, etag: hex.EncodeToString(sum[:]), modified: time.Now().UTC().Truncate(time.Second), header: header}
+	f.objects[key] = object
+
+	w.Header().Set("ETag", `"`+object.etag+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (f *fakeS3) get(w http.ResponseWriter, r *http.Request, key string) {
+	object, ok := f.objects[key]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+
+	for name, values := range object.header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("ETag", `"`+object.etag+`"`)
+	w.Header().Set("Last-Modified", object.modified.Format(http.TimeFormat))
+
+	data, status := object.data, http.StatusOK
+	if spec := strings.TrimPrefix(r.Header.Get("Range"), "bytes="); spec != "" {
+		startStr, endStr, _ := strings.Cut(spec, "-")
+		start, _ := strconv.Atoi(startStr)
+		if start >= len(data) {
+			writeS3Error(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+			return
+		}
+		end, err := strconv.Atoi(endStr)
+		if err != nil || end >= len(data) {
+			end = len(data) - 1
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data, status = data[start:end+1], http.StatusPartialContent
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		MaxKeys     int
+		IsTruncated bool
+		Contents    []content
+	}{Name: f.bucket, Prefix: query.Get("prefix"), MaxKeys: 1000}
+
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > query.Get("start-after") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		object := f.objects[key]
+		result.Contents = append(result.Contents, content{
+			Key: key, LastModified: object.modified.Format(time.RFC3339), ETag: `"` + object.etag + `"`, Size: len(object.data),
+		})
+	}
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+// readS3Body returns the uploaded content, decoding the aws-chunked encoding of streaming signatures
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeStr, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeStr, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, nil
+		}
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+func newTestS3Config(endpoint string) Config {
+	config := DefaultConfig()
+	config.S3 = S3Config{Endpoint: endpoint, Region: "us-east-1", Bucket: "objects", AccessKey: "access", SecretKey: "secret", PathStyle: true}
+	return config
+}
+
+func TestS3RoundTrip(t *testing.T) {
+	fake := newFakeS3("objects")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	service, err := NewS3StorageService(newTestS3Config(server.Listener.Addr().String()), zap.NewNop())
+	require.NoError(t, err)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	content := "This is a test object"
+
+	require.NoError(t, putString(ctx, service, "test123", content, PutOptions{ContentType: "text/plain", UserMetadata: map[string]string{"Owner": "sync"}}))
+
+	obj, info, err := service.GetObject(ctx, "test123", GetOptions{})
+	require.NoError(t, err)
+	data, _ := io.ReadAll(obj)
+	obj.Close()
+	sum := md5.Sum([]byte(content))
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, hex.EncodeToString(sum[:]), info.ETag)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, map[string]string{"Owner": "sync"}, info.UserMetadata)
+
+	obj, info, err = service.GetObject(ctx, "test123", GetOptions{Range: &ByteRange{Start: 10, End: 13}})
+	require.NoError(t, err)
+	data, _ = io.ReadAll(obj)
+	obj.Close()
+	assert.Equal(t, "test", string(data))
+	assert.Equal(t, int64(4), info.Size)
+	_, _, err = service.GetObject(ctx, "test123", GetOptions{Range: &ByteRange{Start: 100, End: 200}})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	stat, err := service.StatObject(ctx, "test123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), stat.Size)
+
+	// Write conditions are passed on to the service
+	assert.ErrorIs(t, putString(ctx, service, "test123", "other", PutOptions{IfNoneMatch: "*"}), ErrPreconditionFailed)
+	assert.ErrorIs(t, putString(ctx, service, "missing", "other", PutOptions{IfMatch: "*"}), ErrPreconditionFailed)
+	assert.NoError(t, putString(ctx, service, "test123", "v2", PutOptions{IfMatch: stat.ETag}))
+
+	require.NoError(t, service.DeleteObject(ctx, "test123"))
+	require.NoError(t, service.DeleteObject(ctx, "test123"))
+	_, _, err = service.GetObject(ctx, "test123", GetOptions{})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.StatObject(ctx, "test123")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Every request named the bucket in its path
+	for _, host := range fake.hosts {
+		assert.Equal(t, server.Listener.Addr().String(), host)
+	}
+}
+
+func TestS3ListObjects(t *testing.T) {
+	fake := newFakeS3("objects")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	service, err := NewS3StorageService(newTestS3Config(server.Listener.Addr().String()), zap.NewNop())
+	require.NoError(t, err)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	for _, id := range []string{"b2", "a1", "c3", "a2", "a3"} {
+		require.NoError(t, putString(ctx, service, id, "content", PutOptions{}))
+	}
+	// Keys written by other applications are not valid object IDs
+	fake.objects["a-foreign/key"] = fakeS3Object{data: []byte("x"), etag: "x"}
+
+	first, err := service.ListObjects(ctx, ListOptions{Prefix: "a", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2"}, objectIDs(first.Objects))
+	assert.True(t, first.IsTruncated)
+
+	second, err := service.ListObjects(ctx, ListOptions{Prefix: "a", Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a3"}, objectIDs(second.Objects))
+	assert.False(t, second.IsTruncated)
+}
+
+func TestS3VirtualHostAddressing(t *testing.T) {
+	fake := newFakeS3("objects")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	// The bucket host name is resolved to the fake server
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}}
+	config := newTestS3Config("s3.storage.local")
+	config.S3.PathStyle = false
+	service, err := newS3StorageService(config, transport, zap.NewNop())
+	require.NoError(t, err)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	require.NoError(t, putString(ctx, service, "test123", "content", PutOptions{}))
+	_, err = service.StatObject(ctx, "test123")
+	require.NoError(t, err)
+
+	require.NotEmpty(t, fake.hosts)
+	for _, host := range fake.hosts {
+		assert.Equal(t, "objects.s3.storage.local", host)
+	}
+}
+
+func TestS3TLS(t *testing.T) {
+	fake := newFakeS3("objects")
+	server := httptest.NewTLSServer(fake)
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, certificate, 0o600))
+
+	config := newTestS3Config(server.Listener.Addr().String())
+	config.S3.TLS = true
+
+	config.HealthCheckTimeout = 200 * time.Millisecond
+
+	// The test certificate is not trusted without the CA file
+	untrusted, err := NewS3StorageService(config, zap.NewNop())
+	require.NoError(t, err)
+	readiness := untrusted.Readiness()
+	assert.False(t, readiness.Ready)
+	assert.Len(t, readiness.FailingNodes, 1)
+
+	config.S3.CAFile = caFile
+	trusted, err := NewS3StorageService(config, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, Readiness{Ready: true, ReadyNodes: 1, RequiredNodes: 1}, trusted.Readiness())
+
+	config.S3.CAFile = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = NewS3StorageService(config, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestS3Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{name: "Missing Object", err: minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound}, expected: ErrNotFound},
+		{name: "Failed Condition", err: minio.ErrorResponse{Code: "PreconditionFailed", StatusCode: http.StatusPreconditionFailed}, expected: ErrPreconditionFailed},
+		{name: "Too Large", err: minio.ErrorResponse{Code: "EntityTooLarge", StatusCode: http.StatusBadRequest}, expected: ErrTooLarge},
+		{name: "Service Error", err: minio.ErrorResponse{Code: "SlowDown", StatusCode: http.StatusServiceUnavailable}, expected: ErrUnavailable},
+		{name: "Connection Error", err: errors.New("connection refused"), expected: ErrUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, s3Error(tc.err, "failed to stat object"), tc.expected)
+		})
+	}
+
+	// Access errors are passed on as internal errors
+	err := s3Error(minio.ErrorResponse{Code: "AccessDenied", Message: "Access Denied.", StatusCode: http.StatusForbidden}, "failed to stat object")
+	assert.NotErrorIs(t, err, ErrUnavailable)
+	assert.EqualError(t, err, "failed to stat object: Access Denied.")
+}
+
+func TestS3Validation(t *testing.T) {
+	// Nothing listens on the endpoint once the server is closed
+	server := httptest.NewServer(newFakeS3("objects"))
+	server.Close()
+
+	config := newTestS3Config(server.Listener.Addr().String())
+	config.HealthCheckTimeout = 200 * time.Millisecond
+	service, err := NewS3StorageService(config, zap.NewNop())
+	require.NoError(t, err)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	_, err = service.StatObject(ctx, "test-123")
+	assert.ErrorIs(t, err, ErrInvalidID)
+	assert.False(t, service.Readiness().Ready)
+
+	_, err = NewS3StorageService(newTestS3Config("https://s3.storage.local"), zap.NewNop())
+	assert.EqualError(t, err, `s3 endpoint "https://s3.storage.local" must not contain a scheme, TLS is selected separately`)
+	_, err = NewS3StorageService(DefaultConfig(), zap.NewNop())
+	assert.EqualError(t, err, "s3 storage needs an endpoint and a bucket")
+}
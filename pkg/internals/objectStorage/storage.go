@@ -3,62 +3,258 @@ package objectStorage
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/dnsDiscovery"
 	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/fileDiscovery"
 	"go.uber.org/zap"
 )
 
+// Storage types selected with the storageType flag
 const (
-	minioStorage = "minio"
+	minioStorage      = "minio"
+	filesystemStorage = "fs"
+	memoryStorage     = "memory"
+	s3Storage         = "s3"
+	tieredStorage     = "tiered"
 )
 
 //go:generate counterfeiter -o fakes/InterfaceObjectStorage.go --fake-name InterfaceObjectStorage . ObjectStorage
 type ObjectStorage interface {
-	GetObject(ctx *gin.Context, objectID string) (io.ReadCloser, error)
-	PutObject(ctx *gin.Context, objectID string, data io.Reader, size int64) error
-	// ListObject(nodeId string, bucketName string)
+	GetObject(ctx *gin.Context, objectID string, opts GetOptions) (io.ReadCloser, ObjectInfo, error)
+	PutObject(ctx *gin.Context, objectID string, data io.Reader, size int64, opts PutOptions) error
+	StatObject(ctx *gin.Context, objectID string) (ObjectInfo, error)
+	DeleteObject(ctx *gin.Context, objectID string) error
+	ListObjects(ctx *gin.Context, opts ListOptions) (*ListResult, error)
 }
 
-type objectStorageFactory struct {
+// Unwrapper is implemented by storages built on top of other storages
+type Unwrapper interface {
+	Unwrap() []ObjectStorage
+}
+
+// Find returns the first storage implementing T, looking at storage itself before the storages
+// it is built on. This keeps capabilities such as the rebalancer available when a backend is wrapped.
+func Find[T any](storage ObjectStorage) (T, bool) {
+	if found, ok := storage.(T); ok {
+		return found, true
+	}
+	if wrapper, ok := storage.(Unwrapper); ok {
+		for _, inner := range wrapper.Unwrap() {
+			if found, ok := Find[T](inner); ok {
+				return found, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// ObjectInfo describes a stored object without its content
+type ObjectInfo struct {
+	ID                 string            `json:"id"`
+	Size               int64             `json:"size"`
+	ETag               string            `json:"etag"`
+	LastModified       time.Time         `json:"last_modified"`
+	ContentType        string            `json:"content_type,omitempty"`
+	ContentDisposition string            `json:"content_disposition,omitempty"`
+	CacheControl       string            `json:"cache_control,omitempty"`
+	UserMetadata       map[string]string `json:"user_metadata,omitempty"`
+}
+
+// listed returns the metadata listings carry, which is all MinIO lists without a request per object
+func (i ObjectInfo) listed() ObjectInfo {
+	return ObjectInfo{ID: i.ID, Size: i.Size, ETag: i.ETag, LastModified: i.LastModified}
+}
+
+// ByteRange is an inclusive range of byte offsets within an object
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Length returns the number of bytes covered by the range
+func (r ByteRange) Length() int64 {
+	return r.End - r.Start + 1
+}
+
+// GetOptions controls how an object is retrieved. When Range is set only those bytes
+// are returned and ObjectInfo.Size is the length of the range.
+type GetOptions struct {
+	Range *ByteRange
+}
+
+// PutOptions carries the metadata stored alongside an object.
+// UserMetadata keys are stored without any header prefix.
+//
+// IfMatch only stores the object if its current ETag matches, IfNoneMatch only if it does not.
+// Both accept "*" to match any existing object, so IfNoneMatch "*" makes the write create-only.
+type PutOptions struct {
+	ContentType        string
+	ContentDisposition string
+	CacheControl       string
+	UserMetadata       map[string]string
+	IfMatch            string
+	IfNoneMatch        string
+}
+
+// checkConditions checks the conditions of a write against the current version of the object,
+// which is nil when the object does not exist
+func (o PutOptions) checkConditions(current *ObjectInfo) error {
+	if o.IfMatch != "" && (current == nil || (o.IfMatch != "*" && o.IfMatch != current.ETag)) {
+		return ErrPreconditionFailed
+	}
+	if o.IfNoneMatch != "" && current != nil && (o.IfNoneMatch == "*" || o.IfNoneMatch == current.ETag) {
+		return ErrPreconditionFailed
+	}
+	return nil
 }
 
-// StorageGeneric shall be a generic implementation of the object storage interface
-// There can be some object storage which shall use this common method for storing and retrieval
-// of objects
-type StorageGeneric struct {
+// ListOptions selects a page of objects. An empty Cursor starts from the beginning
+// and a zero Limit uses the default page size.
+type ListOptions struct {
+	Prefix string
+	Limit  int
+	Cursor string
 }
 
-func newStorageGeneric(logger *zap.Logger) ObjectStorage {
+// ListResult is a single page of a listing. NextCursor is only set when more objects follow.
+// Partial is set when some nodes could not be listed, these are named in FailedNodes.
+type ListResult struct {
+	Objects     []ObjectInfo `json:"objects"`
+	NextCursor  string       `json:"next_cursor,omitempty"`
+	IsTruncated bool         `json:"is_truncated"`
+	Partial     bool         `json:"partial"`
+	FailedNodes []string     `json:"failed_nodes,omitempty"`
+}
+
+type objectStorageFactory struct {
+}
+
+func newStorageGeneric(config Config, logger *zap.Logger) ObjectStorage {
 	// log := internals.GetLogger(c)
-	dockerClient, err := docker.NewClient()
+	discovery, err := newDiscovery(config, logger)
 	if err != nil {
-		logger.Fatal("Failed to create Docker client: %v", zap.Error(err))
+		logger.Fatal("Failed to create node discovery", zap.String("discovery", config.Discovery), zap.Error(err))
 	}
 
-	// Discover Minio nodes
-	minioNodes, err := dockerClient.DiscoverMinioNodes(context.Background())
+	// Discover Minio nodes, nodes that are not found yet join once discovery reports them
+	minioNodes, err := discovery.DiscoverMinioNodes(context.Background())
 	if err != nil {
-		logger.Fatal("Failed to discover Minio nodes: %v", zap.Error(err))
+		logger.Error("Failed to discover Minio nodes", zap.Error(err))
 	}
 
 	if len(minioNodes) == 0 {
-		logger.Fatal("No Minio nodes found")
+		logger.Warn("No Minio nodes found yet")
 	}
 
-	logger.Info("Discovered Minio nodes", zap.Int("minio nodes", len(minioNodes)))
+	logger.Info("Discovered Minio nodes", zap.String("discovery", config.Discovery), zap.Int("minio nodes", len(minioNodes)))
+	for _, node := range minioNodes {
+		logger.Info("Discovered Minio node", zap.String("node_name", node.Name), zap.String("zone", node.Zone), zap.Int("weight", node.Weight))
+	}
 
 	// Create minio service
-	minioStorageService := NewminioStorageService(minioNodes, logger)
+	minioStorageService := NewminioStorageService(minioNodes, config, logger)
+
+	// Follow nodes that join or leave while the gateway is running
+	go discovery.Watch(context.Background(), logger, minioStorageService.UpdateNodes)
+
 	return minioStorageService
 }
 
-func (p *objectStorageFactory) GetObjectStorage(objectStorageType string, logger *zap.Logger) ObjectStorage {
-	switch {
-	case (objectStorageType == minioStorage):
-		return newStorageGeneric(logger)
+// newDiscovery creates the configured node discovery
+func newDiscovery(config Config, logger *zap.Logger) (docker.Discovery, error) {
+	switch config.Discovery {
+	case DiscoveryFile:
+		return fileDiscovery.New(config.NodesFile, fileDiscovery.DefaultPollInterval), nil
+	case DiscoveryDNS:
+		return dnsDiscovery.New(config.DNS, logger)
+	default:
+		return docker.NewClient(config.Docker, logger)
+	}
+}
+
+// newFilesystemStorage creates the storage keeping objects in a local directory
+func newFilesystemStorage(config Config, logger *zap.Logger) ObjectStorage {
+	service, err := NewFilesystemStorageService(config.FilesystemRoot, logger)
+	if err != nil {
+		logger.Fatal("Failed to create filesystem storage", zap.String("root", config.FilesystemRoot), zap.Error(err))
+	}
+	return service
+}
+
+// newS3Storage creates the storage keeping objects in a bucket of an S3 compatible service
+func newS3Storage(config Config, logger *zap.Logger) ObjectStorage {
+	service, err := NewS3StorageService(config, logger)
+	if err != nil {
+		logger.Fatal("Failed to create S3 storage", zap.String("endpoint", config.S3.Endpoint), zap.String("bucket", config.S3.Bucket), zap.Error(err))
+	}
+	return service
+}
+
+// newTieredStorage creates the storage moving objects from a hot to a cold tier, both created by the factory
+func (p *objectStorageFactory) newTieredStorage(config Config, logger *zap.Logger) ObjectStorage {
+	if err := config.validateTiers(); err != nil {
+		logger.Fatal("Invalid tiered storage configuration", zap.Error(err))
+	}
+
+	hot := p.newObjectStorage(config.Tiered.HotType, config, logger.With(zap.String("tier", "hot")))
+	cold := p.newObjectStorage(config.Tiered.ColdType, config.coldTierConfig(), logger.With(zap.String("tier", "cold")))
+	service, err := NewTieredStorageService(hot, cold, config.Tiered, logger)
+	if err != nil {
+		logger.Fatal("Failed to create tiered storage", zap.Error(err))
+	}
+	return service
+}
+
+// newEncryptedStorage wraps a storage so the objects are encrypted before they are stored
+func newEncryptedStorage(storage ObjectStorage, config Config, logger *zap.Logger) ObjectStorage {
+	keys, err := LoadMasterKeys(config.EncryptionKeyFile)
+	if err != nil {
+		logger.Fatal("Failed to load master keys", zap.String("key_file", config.EncryptionKeyFile), zap.Error(err))
+	}
+	service := NewEncryptedStorageService(storage, keys, logger)
+
+	if config.EncryptionRotate {
+		go func() {
+			rotated, err := service.rotate(backgroundContext(logger))
+			if err != nil {
+				logger.Error("Key rotation failed", zap.Int("rotated", rotated), zap.Error(err))
+				return
+			}
+			logger.Info("Key rotation completed", zap.Int("rotated", rotated), zap.String("current_key", keys.currentID()))
+		}()
+	}
+	return service
+}
+
+// GetObjectStorage creates the storage of the given type, encrypting the objects when a key file is configured
+func (p *objectStorageFactory) GetObjectStorage(objectStorageType string, config Config, logger *zap.Logger) ObjectStorage {
+	storage := p.newObjectStorage(objectStorageType, config, logger)
+	if config.EncryptionKeyFile == "" {
+		return storage
+	}
+	return newEncryptedStorage(storage, config, logger)
+}
+
+// newObjectStorage creates the storage of the given type
+func (p *objectStorageFactory) newObjectStorage(objectStorageType string, config Config, logger *zap.Logger) ObjectStorage {
+	switch objectStorageType {
+	case minioStorage:
+		return newStorageGeneric(config, logger)
+	case filesystemStorage:
+		return newFilesystemStorage(config, logger)
+	case memoryStorage:
+		return NewMemoryStorageService(config.MemoryMaxBytes, logger)
+	case s3Storage:
+		return newS3Storage(config, logger)
+	case tieredStorage:
+		return p.newTieredStorage(config, logger)
 	default:
-		return newStorageGeneric(logger)
+		logger.Fatal("Unknown storage type", zap.String("storage_type", objectStorageType))
+		return nil
 	}
 }
 
@@ -0,0 +1,544 @@
+package objectStorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	utils "github.com/singhmeghna79/homework-object-storage/pkg"
+	"go.uber.org/zap"
+)
+
+// TieredConfig describes the tiers of the tiered storage type and when objects move between them
+type TieredConfig struct {
+	// HotType is the storage type new objects are written to
+	HotType string
+	// ColdType is the storage type objects are demoted to
+	ColdType string
+	// MaxAge demotes objects last written longer ago, 0 disables it
+	MaxAge time.Duration
+	// MaxIdle demotes objects not read for longer, 0 disables it. Reads are tracked in memory,
+	// after a restart the last write counts as the last read.
+	MaxIdle time.Duration
+	// MinSize keeps objects smaller than this many bytes in the hot tier
+	MinSize int64
+	// Interval is the time between demotion passes, 0 disables them
+	Interval time.Duration
+	// Promote moves objects read from the cold tier back to the hot tier
+	Promote bool
+	// ColdFilesystemRoot is the directory of a cold fs tier, empty uses the FilesystemRoot of the storage
+	ColdFilesystemRoot string
+	// ColdS3Bucket is the bucket of a cold s3 tier, empty uses the S3 bucket of the storage
+	ColdS3Bucket string
+}
+
+// DefaultTieredConfig returns the tiering used when no flags are given, which demotes objects
+// from the MinIO nodes to the local filesystem after 30 days
+func DefaultTieredConfig() TieredConfig {
+	return TieredConfig{
+		HotType:  minioStorage,
+		ColdType: filesystemStorage,
+		MaxAge:   30 * 24 * time.Hour,
+		Interval: time.Hour,
+	}
+}
+
+// Validate checks that the tiers are known storage types and the policy is not negative
+func (c TieredConfig) Validate() error {
+	for _, storageType := range []string{c.HotType, c.ColdType} {
+		switch storageType {
+		case minioStorage, filesystemStorage, memoryStorage, s3Storage:
+		default:
+			return fmt.Errorf("unknown tier storage type %q", storageType)
+		}
+	}
+	if c.MaxAge < 0 || c.MaxIdle < 0 || c.MinSize < 0 || c.Interval < 0 {
+		return fmt.Errorf("tiering policy must not be negative")
+	}
+	return nil
+}
+
+// validateTiers checks the tiers the factory creates from the storage settings. A memory tier must
+// not evict objects, and a cold tier of the hot tier's type needs its own directory or bucket.
+func (c Config) validateTiers() error {
+	if err := c.Tiered.Validate(); err != nil {
+		return err
+	}
+	if c.MemoryMaxBytes > 0 && (c.Tiered.HotType == memoryStorage || c.Tiered.ColdType == memoryStorage) {
+		return fmt.Errorf("memory tiers must not have a memory limit, evicted objects would be lost")
+	}
+
+	cold := c.coldTierConfig()
+	switch {
+	case c.Tiered.HotType != c.Tiered.ColdType:
+	case c.Tiered.HotType == filesystemStorage && filepath.Clean(cold.FilesystemRoot) != filepath.Clean(c.FilesystemRoot):
+	case c.Tiered.HotType == s3Storage && cold.S3.Bucket != c.S3.Bucket:
+	default:
+		return fmt.Errorf("hot and cold tiers of the same storage type need their own directory or bucket")
+	}
+	return nil
+}
+
+// coldTierConfig returns the storage settings the cold tier is created with
+func (c Config) coldTierConfig() Config {
+	cold := c
+	if c.Tiered.ColdFilesystemRoot != "" {
+		cold.FilesystemRoot = c.Tiered.ColdFilesystemRoot
+	}
+	if c.Tiered.ColdS3Bucket != "" {
+		cold.S3.Bucket = c.Tiered.ColdS3Bucket
+	}
+	return cold
+}
+
+// tieredStorageService writes new objects to a hot tier and moves them to a cold tier once the
+// policy selects them. Objects are read from whichever tier holds them.
+//
+// An object lives in a single tier. While it moves it is briefly stored in both, reads then
+// prefer the hot tier. Moves and writes of an object are serialized within the gateway.
+type tieredStorageService struct {
+	hot    ObjectStorage
+	cold   ObjectStorage
+	config TieredConfig
+	locks  objectLocks
+
+	readsMutex sync.Mutex
+	lastRead   map[string]time.Time
+	// promotions holds the IDs of the objects being promoted
+	promotions sync.Map
+
+	logger *zap.Logger
+}
+
+// NewTieredStorageService creates the storage on top of its tiers and starts the demotion passes
+func NewTieredStorageService(hot, cold ObjectStorage, config TieredConfig, logger *zap.Logger) (*tieredStorageService, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	service := &tieredStorageService{
+		hot:      hot,
+		cold:     cold,
+		config:   config,
+		locks:    objectLocks{locks: make(map[string]*objectLock)},
+		lastRead: make(map[string]time.Time),
+		logger:   logger,
+	}
+	if config.Interval > 0 {
+		go service.run(context.Background())
+	}
+
+	logger.Info("Storing objects in tiers",
+		zap.String("hot", config.HotType), zap.String("cold", config.ColdType),
+		zap.Duration("max_age", config.MaxAge), zap.Duration("max_idle", config.MaxIdle),
+		zap.Int64("min_size", config.MinSize), zap.Bool("promote", config.Promote))
+	return service, nil
+}
+
+// Unwrap returns the tiers, so the capabilities of the MinIO cluster stay available
+func (s *tieredStorageService) Unwrap() []ObjectStorage {
+	return []ObjectStorage{s.hot, s.cold}
+}
+
+// PutObject writes the object to the hot tier and removes an older version from the cold tier
+func (s *tieredStorageService) PutObject(ctx *gin.Context, objectID string, data io.Reader, size int64, opts PutOptions) error {
+	logger := utils.GetLogger(ctx)
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
+	}
+
+	unlock := s.locks.lock(objectID)
+	defer unlock()
+
+	// The hot tier checks the conditions against its own objects, those in the cold tier are checked here
+	hotOpts := opts
+	if opts.IfMatch != "" || opts.IfNoneMatch != "" {
+		_, err := s.hot.StatObject(ctx, objectID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		if err != nil {
+			current, err := s.cold.StatObject(ctx, objectID)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+			if err == nil {
+				if err := opts.checkConditions(&current); err != nil {
+					return err
+				}
+				// Another gateway may have written the object to the hot tier meanwhile
+				hotOpts.IfMatch, hotOpts.IfNoneMatch = "", "*"
+			}
+		}
+	}
+
+	if err := s.hot.PutObject(ctx, objectID, data, size, hotOpts); err != nil {
+		return err
+	}
+	s.forgetRead(objectID)
+
+	if err := s.cold.DeleteObject(ctx, objectID); err != nil {
+		// Reads prefer the hot tier, so the stale copy is never served
+		logger.Warn("Failed to delete object from cold tier", zap.String("object_id", objectID), zap.Error(err))
+	}
+	return nil
+}
+
+// GetObject reads the object from the hot tier, or recalls it from the cold tier
+func (s *tieredStorageService) GetObject(ctx *gin.Context, objectID string, opts GetOptions) (io.ReadCloser, ObjectInfo, error) {
+	obj, info, err := s.hot.GetObject(ctx, objectID, opts)
+	if !errors.Is(err, ErrNotFound) {
+		if err == nil {
+			s.recordRead(objectID)
+		}
+		return obj, info, err
+	}
+
+	obj, info, err = s.cold.GetObject(ctx, objectID, opts)
+	if errors.Is(err, ErrNotFound) {
+		// The object may have been promoted meanwhile
+		return s.hot.GetObject(ctx, objectID, opts)
+	}
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	utils.GetLogger(ctx).Info("Recalled object from cold tier", zap.String("object_id", objectID))
+	if s.config.Promote {
+		s.promote(objectID)
+	}
+	return obj, info, nil
+}
+
+// StatObject returns the metadata of the object from the tier holding it. It does not count as a read.
+func (s *tieredStorageService) StatObject(ctx *gin.Context, objectID string) (ObjectInfo, error) {
+	info, err := s.hot.StatObject(ctx, objectID)
+	if !errors.Is(err, ErrNotFound) {
+		return info, err
+	}
+
+	info, err = s.cold.StatObject(ctx, objectID)
+	if errors.Is(err, ErrNotFound) {
+		// The object may have been promoted meanwhile
+		return s.hot.StatObject(ctx, objectID)
+	}
+	return info, err
+}
+
+// DeleteObject removes the object from both tiers
+func (s *tieredStorageService) DeleteObject(ctx *gin.Context, objectID string) error {
+	// Validate object ID
+	if err := validateObjectID(objectID); err != nil {
+		return err
+	}
+
+	unlock := s.locks.lock(objectID)
+	defer unlock()
+
+	if err := s.hot.DeleteObject(ctx, objectID); err != nil {
+		return err
+	}
+	s.forgetRead(objectID)
+	return s.cold.DeleteObject(ctx, objectID)
+}
+
+// ListObjects merges the listings of both tiers into a single page sorted by ID. An object that
+// is moving and listed by both tiers is reported once, with the metadata of the hot tier.
+func (s *tieredStorageService) ListObjects(ctx *gin.Context, opts ListOptions) (*ListResult, error) {
+	limit, _, err := normalizeListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.Limit = limit
+
+	hot, err := s.hot.ListObjects(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	cold, err := s.cold.ListObjects(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListResult{
+		Objects:     []ObjectInfo{},
+		Partial:     hot.Partial || cold.Partial,
+		FailedNodes: append(slices.Clone(hot.FailedNodes), cold.FailedNodes...),
+	}
+	i, j := 0, 0
+	for len(result.Objects) < limit && (i < len(hot.Objects) || j < len(cold.Objects)) {
+		if j == len(cold.Objects) || (i < len(hot.Objects) && hot.Objects[i].ID <= cold.Objects[j].ID) {
+			if j < len(cold.Objects) && hot.Objects[i].ID == cold.Objects[j].ID {
+				j++
+			}
+			result.Objects = append(result.Objects, hot.Objects[i])
+			i++
+		} else {
+			result.Objects = append(result.Objects, cold.Objects[j])
+			j++
+		}
+	}
+
+	remaining := i < len(hot.Objects) || j < len(cold.Objects) || hot.IsTruncated || cold.IsTruncated
+	if remaining && len(result.Objects) > 0 {
+		result.IsTruncated = true
+		result.NextCursor = encodeListCursor(result.Objects[len(result.Objects)-1].ID)
+	}
+	return result, nil
+}
+
+// Readiness combines the readiness of the tiers, tiers without a readiness check are always ready
+func (s *tieredStorageService) Readiness() Readiness {
+	readiness := Readiness{Ready: true}
+	for _, tier := range s.Unwrap() {
+		checker, ok := tier.(ReadinessChecker)
+		if !ok {
+			continue
+		}
+		tierReadiness := checker.Readiness()
+		readiness.Ready = readiness.Ready && tierReadiness.Ready
+		readiness.ReadyNodes += tierReadiness.ReadyNodes
+		readiness.RequiredNodes += tierReadiness.RequiredNodes
+		readiness.FailingNodes = append(readiness.FailingNodes, tierReadiness.FailingNodes...)
+	}
+	return readiness
+}
+
+// run demotes the objects selected by the policy on every interval until ctx is cancelled
+func (s *tieredStorageService) run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		demoted, err := s.demote(backgroundContext(s.logger))
+		if err != nil {
+			s.logger.Error("Demotion pass failed", zap.Int("demoted", demoted), zap.Error(err))
+		} else if demoted > 0 {
+			s.logger.Info("Demotion pass completed", zap.Int("demoted", demoted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// demote moves every object of the hot tier selected by the policy to the cold tier and returns
+// how many were moved. Objects failing to move stay in the hot tier until the next pass.
+func (s *tieredStorageService) demote(ctx *gin.Context) (int, error) {
+	if s.config.MaxAge == 0 && s.config.MaxIdle == 0 {
+		return 0, nil
+	}
+
+	demoted := 0
+	opts := ListOptions{Limit: maxListLimit}
+	for {
+		page, err := s.hot.ListObjects(ctx, opts)
+		if err != nil {
+			return demoted, err
+		}
+
+		for _, info := range page.Objects {
+			if !s.shouldDemote(info) {
+				continue
+			}
+			moved, err := s.demoteObject(ctx, info.ID)
+			if err != nil {
+				s.logger.Warn("Failed to demote object", zap.String("object_id", info.ID), zap.Error(err))
+				continue
+			}
+			if moved {
+				demoted++
+			}
+		}
+
+		if !page.IsTruncated {
+			return demoted, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+// shouldDemote applies the policy to an object of the hot tier
+func (s *tieredStorageService) shouldDemote(info ObjectInfo) bool {
+	if info.Size < s.config.MinSize {
+		return false
+	}
+	if s.config.MaxAge > 0 && time.Since(info.LastModified) >= s.config.MaxAge {
+		return true
+	}
+	if s.config.MaxIdle > 0 && time.Since(s.lastReadOf(info)) >= s.config.MaxIdle {
+		return true
+	}
+	return false
+}
+
+// demoteObject copies an object to the cold tier before removing it from the hot tier. It reports
+// false when the object changed and is no longer selected by the policy.
+func (s *tieredStorageService) demoteObject(ctx *gin.Context, objectID string) (bool, error) {
+	unlock := s.locks.lock(objectID)
+	defer unlock()
+
+	obj, info, err := s.hot.GetObject(ctx, objectID, GetOptions{})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer obj.Close()
+
+	// The object may have been written or read since it was listed
+	if !s.shouldDemote(info) {
+		return false, nil
+	}
+
+	if err := s.cold.PutObject(ctx, objectID, obj, info.Size, putOptionsOf(info)); err != nil {
+		return false, fmt.Errorf("failed to copy object to cold tier: %w", err)
+	}
+	if err := s.hot.DeleteObject(ctx, objectID); err != nil {
+		return false, fmt.Errorf("failed to delete demoted object from hot tier: %w", err)
+	}
+	s.forgetRead(objectID)
+
+	s.logger.Info("Demoted object to cold tier", zap.String("object_id", objectID), zap.Int64("size", info.Size))
+	return true, nil
+}
+
+// promote moves an object read from the cold tier back to the hot tier in the background
+func (s *tieredStorageService) promote(objectID string) {
+	// Concurrent reads of the same object only trigger one promotion
+	if _, running := s.promotions.LoadOrStore(objectID, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer s.promotions.Delete(objectID)
+
+		if err := s.promoteObject(backgroundContext(s.logger), objectID); err != nil {
+			s.logger.Warn("Failed to promote object", zap.String("object_id", objectID), zap.Error(err))
+			return
+		}
+		s.logger.Info("Promoted object to hot tier", zap.String("object_id", objectID))
+	}()
+}
+
+// promoteObject copies an object to the hot tier before removing it from the cold tier
+func (s *tieredStorageService) promoteObject(ctx *gin.Context, objectID string) error {
+	unlock := s.locks.lock(objectID)
+	defer unlock()
+
+	obj, info, err := s.cold.GetObject(ctx, objectID, GetOptions{})
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+
+	// A version in the hot tier is newer than the one being promoted
+	opts := putOptionsOf(info)
+	opts.IfNoneMatch = "*"
+	err = s.hot.PutObject(ctx, objectID, obj, info.Size, opts)
+	if err != nil && !errors.Is(err, ErrPreconditionFailed) {
+		return fmt.Errorf("failed to copy object to hot tier: %w", err)
+	}
+	s.recordRead(objectID)
+
+	if err := s.cold.DeleteObject(ctx, objectID); err != nil {
+		return fmt.Errorf("failed to delete promoted object from cold tier: %w", err)
+	}
+	return nil
+}
+
+// recordRead marks an object of the hot tier as read now
+func (s *tieredStorageService) recordRead(objectID string) {
+	if s.config.MaxIdle == 0 {
+		return
+	}
+	s.readsMutex.Lock()
+	s.lastRead[objectID] = time.Now()
+	s.readsMutex.Unlock()
+}
+
+// forgetRead drops the last read of an object that was written, deleted or demoted
+func (s *tieredStorageService) forgetRead(objectID string) {
+	s.readsMutex.Lock()
+	delete(s.lastRead, objectID)
+	s.readsMutex.Unlock()
+}
+
+// lastReadOf returns when an object was last read, or written when it was not read since
+func (s *tieredStorageService) lastReadOf(info ObjectInfo) time.Time {
+	s.readsMutex.Lock()
+	defer s.readsMutex.Unlock()
+	if read, ok := s.lastRead[info.ID]; ok && read.After(info.LastModified) {
+		return read
+	}
+	return info.LastModified
+}
+
+// putOptionsOf returns the options storing an object with the metadata of info
+func putOptionsOf(info ObjectInfo) PutOptions {
+	return PutOptions{
+		ContentType:        info.ContentType,
+		ContentDisposition: info.ContentDisposition,
+		CacheControl:       info.CacheControl,
+		UserMetadata:       info.UserMetadata,
+	}
+}
+
+// backgroundContext returns the context passed to the tiers outside of a request. Like the
+// contexts of requests it carries the logger.
+func backgroundContext(logger *zap.Logger) *gin.Context {
+	ctx := &gin.Context{}
+	ctx.Set(utils.ContextLoggerKey, logger)
+	return ctx
+}
+
+// objectLocks hands out a lock per object ID. Objects are only locked while they are written,
+// deleted or moved, so the map holds the objects in use.
+type objectLocks struct {
+	mutex sync.Mutex
+	locks map[string]*objectLock
+}
+
+type objectLock struct {
+	sync.Mutex
+	// holders counts the callers holding or waiting for the lock
+	holders int
+}
+
+// lock locks an object and returns the function unlocking it
+func (l *objectLocks) lock(objectID string) func() {
+	l.mutex.Lock()
+	entry, ok := l.locks[objectID]
+	if !ok {
+		entry = &objectLock{}
+		l.locks[objectID] = entry
+	}
+	entry.holders++
+	l.mutex.Unlock()
+
+	entry.Lock()
+	return func() {
+		entry.Unlock()
+
+		l.mutex.Lock()
+		entry.holders--
+		if entry.holders == 0 {
+			delete(l.locks, objectID)
+		}
+		l.mutex.Unlock()
+	}
+}
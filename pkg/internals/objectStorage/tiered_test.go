@@ -0,0 +1,252 @@
+package objectStorage
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestTieredService builds a service keeping hot objects in memory and cold ones on disk, without demotion passes
+func newTestTieredService(t *testing.T, config TieredConfig) (*tieredStorageService, *memoryStorageService, *filesystemStorageService, *gin.Context) {
+	hot, ctx := newTestMemoryService(0)
+	cold, _ := newTestFilesystemService(t)
+	config.HotType, config.ColdType, config.Interval = memoryStorage, filesystemStorage, 0
+
+	service, err := NewTieredStorageService(hot, cold, config, zap.NewNop())
+	require.NoError(t, err)
+	return service, hot, cold, ctx
+}
+
+func readString(t *testing.T, ctx *gin.Context, storage ObjectStorage, objectID string, opts GetOptions) string {
+	obj, _, err := storage.GetObject(ctx, objectID, opts)
+	require.NoError(t, err)
+	defer obj.Close()
+	data, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestTieredDemotionByAge(t *testing.T) {
+	service, hot, cold, ctx := newTestTieredService(t, TieredConfig{MaxAge: 50 * time.Millisecond, MinSize: 4})
+
+	require.NoError(t, putString(ctx, service, "old", "This is a test object", PutOptions{ContentType: "text/plain", UserMetadata: map[string]string{"Owner": "sync"}}))
+	require.NoError(t, putString(ctx, service, "small", "abc", PutOptions{}))
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, putString(ctx, service, "young", "content", PutOptions{}))
+
+	demoted, err := service.demote(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, demoted)
+
+	// Only the old object is large enough to move
+	_, err = hot.StatObject(ctx, "old")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = cold.StatObject(ctx, "old")
+	assert.NoError(t, err)
+	_, err = hot.StatObject(ctx, "small")
+	assert.NoError(t, err)
+	_, err = hot.StatObject(ctx, "young")
+	assert.NoError(t, err)
+
+	// Demoted objects are recalled transparently with their metadata
+	assert.Equal(t, "This is a test object", readString(t, ctx, service, "old", GetOptions{}))
+	assert.Equal(t, "test", readString(t, ctx, service, "old", GetOptions{Range: &ByteRange{Start: 10, End: 13}}))
+	info, err := service.StatObject(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, int64(21), info.Size)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, map[string]string{"Owner": "sync"}, info.UserMetadata)
+
+	// Without promotion the object stays cold
+	_, err = hot.StatObject(ctx, "old")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestTieredDemotionByIdle(t *testing.T) {
+	service, hot, _, ctx := newTestTieredService(t, TieredConfig{MaxIdle: 50 * time.Millisecond})
+
+	require.NoError(t, putString(ctx, service, "read", "content", PutOptions{}))
+	require.NoError(t, putString(ctx, service, "unread", "content", PutOptions{}))
+	time.Sleep(60 * time.Millisecond)
+
+	// A read keeps the object hot, a stat does not
+	readString(t, ctx, service, "read", GetOptions{})
+	_, err := service.StatObject(ctx, "unread")
+	require.NoError(t, err)
+
+	demoted, err := service.demote(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, demoted)
+	_, err = hot.StatObject(ctx, "read")
+	assert.NoError(t, err)
+	_, err = hot.StatObject(ctx, "unread")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestTieredPromotion(t *testing.T) {
+	service, hot, cold, ctx := newTestTieredService(t, TieredConfig{Promote: true})
+	require.NoError(t, putString(ctx, cold, "test123", "This is a test object", PutOptions{ContentType: "text/plain"}))
+
+	assert.Equal(t, "test", readString(t, ctx, service, "test123", GetOptions{Range: &ByteRange{Start: 10, End: 13}}))
+
+	// The whole object moves back to the hot tier
+	assert.Eventually(t, func() bool {
+		_, err := cold.StatObject(ctx, "test123")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "This is a test object", readString(t, ctx, hot, "test123", GetOptions{}))
+	info, err := hot.StatObject(ctx, "test123")
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", info.ContentType)
+}
+
+func TestTieredConditionalPut(t *testing.T) {
+	service, hot, cold, ctx := newTestTieredService(t, TieredConfig{})
+	require.NoError(t, putString(ctx, cold, "cold", "v1", PutOptions{}))
+	current, err := cold.StatObject(ctx, "cold")
+	require.NoError(t, err)
+
+	// Conditions see objects in the cold tier
+	assert.ErrorIs(t, putString(ctx, service, "cold", "v2", PutOptions{IfNoneMatch: "*"}), ErrPreconditionFailed)
+	assert.ErrorIs(t, putString(ctx, service, "cold", "v2", PutOptions{IfMatch: "0123"}), ErrPreconditionFailed)
+	assert.ErrorIs(t, putString(ctx, service, "missing", "v2", PutOptions{IfMatch: "*"}), ErrPreconditionFailed)
+
+	// A write replaces the cold version with a hot one
+	require.NoError(t, putString(ctx, service, "cold", "v2", PutOptions{IfMatch: current.ETag}))
+	assert.Equal(t, "v2", readString(t, ctx, hot, "cold", GetOptions{}))
+	_, err = cold.StatObject(ctx, "cold")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, service.DeleteObject(ctx, "cold"))
+	_, err = service.StatObject(ctx, "cold")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.StatObject(ctx, "not-valid")
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
+
+func TestTieredListObjects(t *testing.T) {
+	service, hot, cold, ctx := newTestTieredService(t, TieredConfig{})
+	for _, id := range []string{"a1", "a3", "c1"} {
+		require.NoError(t, putString(ctx, hot, id, "content", PutOptions{}))
+	}
+	// a3 is moving and stored in both tiers
+	for _, id := range []string{"a2", "a3", "a4"} {
+		require.NoError(t, putString(ctx, cold, id, "content", PutOptions{}))
+	}
+
+	first, err := service.ListObjects(ctx, ListOptions{Prefix: "a", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2"}, objectIDs(first.Objects))
+	assert.True(t, first.IsTruncated)
+
+	second, err := service.ListObjects(ctx, ListOptions{Prefix: "a", Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a3", "a4"}, objectIDs(second.Objects))
+	assert.False(t, second.IsTruncated)
+
+	all, err := service.ListObjects(ctx, ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2", "a3", "a4", "c1"}, objectIDs(all.Objects))
+}
+
+func TestTieredCapabilities(t *testing.T) {
+	hot := newTestService(newTestNodes(1), DefaultConfig())
+	config := DefaultTieredConfig()
+	config.ColdType, config.Interval = memoryStorage, 0
+	service, err := NewTieredStorageService(hot, NewMemoryStorageService(0, zap.NewNop()), config, zap.NewNop())
+	require.NoError(t, err)
+
+	// The readiness of the tiers is combined, the memory tier is always ready
+	checker, ok := Find[ReadinessChecker](service)
+	require.True(t, ok)
+	assert.Equal(t, Readiness{
+		RequiredNodes: 1,
+		FailingNodes:  []FailingNode{{Name: "node-1", Reason: "client not initialized"}},
+	}, checker.Readiness())
+
+	// The capabilities of the MinIO cluster are found through the tiered storage
+	provider, ok := Find[RebalancerProvider](service)
+	require.True(t, ok)
+	assert.Same(t, hot, provider)
+	_, ok = Find[HealthCheckerProvider](service)
+	assert.True(t, ok)
+	_, ok = Find[RebalancerProvider](NewMemoryStorageService(0, zap.NewNop()))
+	assert.False(t, ok)
+
+	config.ColdType, config.MaxAge = filesystemStorage, -time.Hour
+	_, err = NewTieredStorageService(hot, hot, config, zap.NewNop())
+	assert.EqualError(t, err, "tiering policy must not be negative")
+	config.ColdType = tieredStorage
+	_, err = NewTieredStorageService(hot, hot, config, zap.NewNop())
+	assert.EqualError(t, err, `unknown tier storage type "tiered"`)
+}
+
+func TestTieredConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		hot, cold     string
+		maxBytes      int64
+		coldRoot      string
+		coldBucket    string
+		expectedError string
+	}{
+		{name: "Default Tiers", hot: minioStorage, cold: filesystemStorage},
+		{name: "Unlimited Memory Hot Tier", hot: memoryStorage, cold: filesystemStorage},
+		{name: "Limited Memory Hot Tier", hot: memoryStorage, cold: filesystemStorage, maxBytes: 1024, expectedError: "memory tiers must not have a memory limit, evicted objects would be lost"},
+		{name: "Limited Memory Cold Tier", hot: minioStorage, cold: memoryStorage, maxBytes: 1024, expectedError: "memory tiers must not have a memory limit, evicted objects would be lost"},
+		{name: "Separate Directories", hot: filesystemStorage, cold: filesystemStorage, coldRoot: "archive"},
+		{name: "Shared Directory", hot: filesystemStorage, cold: filesystemStorage, coldRoot: "data/", expectedError: "hot and cold tiers of the same storage type need their own directory or bucket"},
+		{name: "Separate Buckets", hot: s3Storage, cold: s3Storage, coldBucket: "archive"},
+		{name: "Shared Bucket", hot: s3Storage, cold: s3Storage, expectedError: "hot and cold tiers of the same storage type need their own directory or bucket"},
+		{name: "Same MinIO Cluster", hot: minioStorage, cold: minioStorage, expectedError: "hot and cold tiers of the same storage type need their own directory or bucket"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.S3.Bucket, config.MemoryMaxBytes = "objects", tc.maxBytes
+			config.Tiered.HotType, config.Tiered.ColdType = tc.hot, tc.cold
+			config.Tiered.ColdFilesystemRoot, config.Tiered.ColdS3Bucket = tc.coldRoot, tc.coldBucket
+
+			err := config.validateTiers()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	// The cold tier keeps the other settings of the storage
+	config := DefaultConfig()
+	config.S3.Bucket, config.Tiered.ColdS3Bucket, config.Tiered.ColdFilesystemRoot = "objects", "archive", "cold"
+	cold := config.coldTierConfig()
+	assert.Equal(t, "archive", cold.S3.Bucket)
+	assert.Equal(t, "cold", cold.FilesystemRoot)
+	assert.Equal(t, config.S3.Endpoint, cold.S3.Endpoint)
+	assert.Equal(t, "objects", config.S3.Bucket)
+}
+
+// The fake S3 service is usable as a cold bucket behind the tiered storage
+func TestTieredS3ColdTier(t *testing.T) {
+	server := httptest.NewServer(newFakeS3("objects"))
+	defer server.Close()
+
+	cold, err := NewS3StorageService(newTestS3Config(server.Listener.Addr().String()), zap.NewNop())
+	require.NoError(t, err)
+	hot, ctx := newTestMemoryService(0)
+	service, err := NewTieredStorageService(hot, cold, TieredConfig{HotType: memoryStorage, ColdType: s3Storage, MaxAge: time.Nanosecond}, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, putString(ctx, service, "test123", "This is a test object", PutOptions{}))
+	demoted, err := service.demote(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, demoted)
+	assert.Equal(t, "This is a test object", readString(t, ctx, service, "test123", GetOptions{}))
+}
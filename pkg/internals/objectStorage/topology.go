@@ -0,0 +1,138 @@
+package objectStorage
+
+import (
+	"errors"
+	"maps"
+
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"go.uber.org/zap"
+)
+
+// TopologyChange describes a change of the node set
+type TopologyChange struct {
+	Added   []docker.MinioNode
+	Removed []docker.MinioNode
+	// Reweighted are the nodes that were known before and whose weight changed
+	Reweighted []docker.MinioNode
+	// Nodes is the node set after the change
+	Nodes []docker.MinioNode
+}
+
+// OnTopologyChange registers a hook that is called after every change of the node set that moves objects
+func (s *minioStorageService) OnTopologyChange(hook func(TopologyChange)) {
+	s.clientsMutex.Lock()
+	s.topologyHooks = append(s.topologyHooks, hook)
+	s.clientsMutex.Unlock()
+}
+
+// UpdateNodes replaces the node set with the nodes discovered at runtime. Clients are created in the
+// background for new nodes and nodes whose endpoint changed, and dropped for nodes that left. When nodes
+// joined, left or were reweighted, the previous placement is kept so reads can find objects that have not
+// been migrated yet. Other changes of known nodes, such as a new address, only update the node set.
+func (s *minioStorageService) UpdateNodes(nodes []docker.MinioNode) {
+	s.topologyMutex.Lock()
+	defer s.topologyMutex.Unlock()
+
+	s.clientsMutex.RLock()
+	current := make(map[string]docker.MinioNode, len(s.nodes))
+	for _, node := range s.nodes {
+		current[node.ID] = node
+	}
+	s.clientsMutex.RUnlock()
+
+	change := TopologyChange{Nodes: nodes}
+	var updated []docker.MinioNode
+	discovered := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		discovered[node.ID] = true
+		old, known := current[node.ID]
+		switch {
+		case !known:
+			change.Added = append(change.Added, node)
+		case old.Weight != node.Weight:
+			change.Reweighted = append(change.Reweighted, node)
+		case !sameNode(old, node):
+			updated = append(updated, node)
+		}
+		if !known || !sameEndpoint(old, node) {
+			// Clients are initialised in the background, the node serves requests once it is ready
+			s.connect(node)
+		}
+	}
+	for _, node := range current {
+		if !discovered[node.ID] {
+			change.Removed = append(change.Removed, node)
+		}
+	}
+
+	moved := len(change.Added) > 0 || len(change.Removed) > 0 || len(change.Reweighted) > 0
+	if !moved && len(updated) == 0 {
+		return
+	}
+
+	s.clientsMutex.Lock()
+	if moved {
+		s.previousPlacement, s.previousReplicationFactor = s.placement, s.replicationFactor()
+	}
+	s.nodes = append(nodes[:0:0], nodes...)
+	if moved {
+		s.placement = newPlacement(s.nodes, s.config)
+	}
+	for _, node := range change.Removed {
+		if attempt, ok := s.connecting[node.ID]; ok {
+			attempt.cancel()
+			delete(s.connecting, node.ID)
+		}
+		delete(s.clients, node.ID)
+		delete(s.clientErrors, node.ID)
+		delete(s.breakers, node.ID)
+	}
+	hooks := append(s.topologyHooks[:0:0], s.topologyHooks...)
+	s.clientsMutex.Unlock()
+
+	if !moved {
+		s.logger.Info("Storage nodes updated", zap.Strings("updated", nodeNames(updated)))
+		return
+	}
+
+	s.logger.Info("Storage topology changed",
+		zap.Strings("added", nodeNames(change.Added)),
+		zap.Strings("removed", nodeNames(change.Removed)),
+		zap.Strings("reweighted", nodeNames(change.Reweighted)),
+		zap.Int("minio nodes", len(nodes)))
+	if s.config.ReplicationFactor > len(nodes) {
+		s.logger.Warn("Replication factor exceeds the number of nodes",
+			zap.Int("replication_factor", s.config.ReplicationFactor), zap.Int("minio nodes", len(nodes)))
+	}
+
+	for _, hook := range hooks {
+		hook(change)
+	}
+}
+
+// rebalanceOnChange restarts the rebalancer so it migrates the objects of a new topology
+func (s *minioStorageService) rebalanceOnChange(change TopologyChange) {
+	s.rebalancer.Stop()
+	if err := s.rebalancer.Start(); err != nil && !errors.Is(err, ErrRebalanceRunning) {
+		s.logger.Error("Failed to start rebalance after topology change", zap.Error(err))
+	}
+}
+
+// sameEndpoint reports whether a client for one node can be used for the other
+func sameEndpoint(a, b docker.MinioNode) bool {
+	return a.IPAddress == b.IPAddress && a.Port == b.Port && a.AccessKey == b.AccessKey && a.SecretKey == b.SecretKey
+}
+
+// sameNode reports whether two discoveries of a node describe it the same way
+func sameNode(a, b docker.MinioNode) bool {
+	return sameEndpoint(a, b) && a.Name == b.Name && a.Zone == b.Zone && a.Weight == b.Weight && maps.Equal(a.Labels, b.Labels)
+}
+
+// nodeNames returns the names of nodes for logging
+func nodeNames(nodes []docker.MinioNode) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
@@ -0,0 +1,162 @@
+package objectStorage
+
+import (
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	docker "github.com/singhmeghna79/homework-object-storage/pkg/internals/dockerClient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateNodes(t *testing.T) {
+	nodes := newTestNodes(3)
+	service := newTestService(nodes, DefaultConfig())
+	for _, node := range nodes {
+		service.clients[node.ID] = &minio.Client{}
+	}
+
+	var changes []TopologyChange
+	service.OnTopologyChange(func(change TopologyChange) {
+		changes = append(changes, change)
+	})
+
+	before := map[string]string{}
+	for i := 0; i < 100; i++ {
+		objectID := fmt.Sprintf("object%d", i)
+		replicas, _ := service.getReplicasForID(objectID)
+		before[objectID] = replicas[0].node.ID
+	}
+
+	// Discovering the same nodes again is not a change
+	service.UpdateNodes(newTestNodes(3))
+	assert.Empty(t, changes)
+	assert.Nil(t, service.previousPlacement)
+
+	service.UpdateNodes(nodes[:2])
+	assert.Len(t, changes, 1)
+	assert.Empty(t, changes[0].Added)
+	assert.Equal(t, nodes[2:], changes[0].Removed)
+	assert.Equal(t, nodes[:2], changes[0].Nodes)
+	assert.NotContains(t, service.clients, nodes[2].ID)
+
+	// Objects are looked up on their previous owners first
+	for objectID, owner := range before {
+		replicas, err := service.getReplicasForID(objectID)
+		assert.NoError(t, err)
+		assert.NotEqual(t, nodes[2].ID, replicas[0].node.ID)
+		assert.Equal(t, []string{owner}, service.previousPlacement.Locate(objectID, service.previousReplicationFactor))
+	}
+}
+
+// newTestNodeServer starts a fake S3 server for a node and returns the node pointing at it
+func newTestNodeServer(t *testing.T, node docker.MinioNode) (docker.MinioNode, *fakeS3) {
+	fake := newFakeS3(bucketName)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	node.IPAddress, node.Port, _ = net.SplitHostPort(server.Listener.Addr().String())
+	node.AccessKey, node.SecretKey = "access", "secret"
+	return node, fake
+}
+
+// waitForClient waits until the background initialisation of a node's client finished
+func waitForClient(t *testing.T, service *minioStorageService, nodeID string) {
+	assert.Eventually(t, func() bool {
+		service.clientsMutex.RLock()
+		defer service.clientsMutex.RUnlock()
+		_, connecting := service.connecting[nodeID]
+		return service.clients[nodeID] != nil && !connecting
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestUpdateNodesAdded(t *testing.T) {
+	nodes := newTestNodes(3)
+	service := newTestService(nodes[:2], DefaultConfig())
+	var changes []TopologyChange
+	service.OnTopologyChange(func(change TopologyChange) {
+		changes = append(changes, change)
+	})
+
+	added, _ := newTestNodeServer(t, nodes[2])
+	service.UpdateNodes([]docker.MinioNode{nodes[0], nodes[1], added})
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, []docker.MinioNode{added}, changes[0].Added)
+	assert.Empty(t, changes[0].Removed)
+	assert.Equal(t, []docker.MinioNode{nodes[0], nodes[1], added}, service.currentNodes())
+	assert.NotNil(t, service.previousPlacement)
+	waitForClient(t, service, added.ID)
+
+	// The new node takes over a share of the objects
+	owned := 0
+	for i := 0; i < 100; i++ {
+		replicas, err := service.getReplicasForID(fmt.Sprintf("object%d", i))
+		require.NoError(t, err)
+		if replicas[0].node.ID == added.ID {
+			owned++
+		}
+	}
+	assert.Positive(t, owned)
+}
+
+func TestUpdateNodesEndpointChange(t *testing.T) {
+	nodes := newTestNodes(2)
+	first, _ := newTestNodeServer(t, nodes[0])
+	service := newTestService([]docker.MinioNode{first, nodes[1]}, DefaultConfig())
+	var changes []TopologyChange
+	service.OnTopologyChange(func(change TopologyChange) {
+		changes = append(changes, change)
+	})
+
+	// The node keeps its ID but moves to another address
+	moved, fake := newTestNodeServer(t, nodes[0])
+	service.UpdateNodes([]docker.MinioNode{moved, nodes[1]})
+
+	// The node set is updated without moving any objects
+	assert.Empty(t, changes)
+	assert.Nil(t, service.previousPlacement)
+	assert.Equal(t, []docker.MinioNode{moved, nodes[1]}, service.currentNodes())
+	waitForClient(t, service, moved.ID)
+
+	// Requests reach the node at its new address
+	service.clientsMutex.RLock()
+	client := service.clients[moved.ID]
+	service.clientsMutex.RUnlock()
+	putOnReplica(t, replica{node: moved, client: client}, "test123", "content")
+	fake.mutex.Lock()
+	assert.Contains(t, fake.objects, "test123")
+	fake.mutex.Unlock()
+}
+
+func TestUpdateNodesReweighted(t *testing.T) {
+	nodes := newTestNodes(3)
+	service := newTestService(nodes, DefaultConfig())
+	var changes []TopologyChange
+	service.OnTopologyChange(func(change TopologyChange) {
+		changes = append(changes, change)
+	})
+
+	heavy := append([]docker.MinioNode{}, nodes...)
+	heavy[0].Weight = 10
+	service.UpdateNodes(heavy)
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, heavy[:1], changes[0].Reweighted)
+	assert.Empty(t, changes[0].Added)
+	assert.NotNil(t, service.previousPlacement)
+
+	// The placement follows the new weight
+	owned := 0
+	for i := 0; i < 300; i++ {
+		replicas, err := service.getReplicasForID(fmt.Sprintf("object%d", i))
+		require.NoError(t, err)
+		if replicas[0].node.ID == nodes[0].ID {
+			owned++
+		}
+	}
+	assert.Greater(t, owned, 200)
+}
@@ -0,0 +1,51 @@
+package placement
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// Names of the available placement strategies
+const (
+	StrategyRing       = "ring"
+	StrategyRendezvous = "rendezvous"
+)
+
+// Placement maps keys to the members responsible for them
+type Placement interface {
+	// Locate returns up to n distinct member IDs responsible for key, in preference order
+	Locate(key string, n int) []string
+	// Members returns the number of distinct members taking part in placement
+	Members() int
+}
+
+// New builds the placement of the named strategy for the members.
+// virtualNodes is only used by the ring strategy.
+func New(strategy string, members []Member, virtualNodes int) (Placement, error) {
+	switch strategy {
+	case StrategyRing:
+		return NewRing(members, virtualNodes), nil
+	case StrategyRendezvous:
+		return NewRendezvous(members), nil
+	default:
+		return nil, fmt.Errorf("unknown placement strategy %q", strategy)
+	}
+}
+
+// hashKey hashes a key for placement. FNV-1a alone clusters similar inputs such as
+// "node#1" and "node#2", so the result is passed through a 64-bit finalizer.
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return mix64(h.Sum64())
+}
+
+// mix64 scrambles the bits of x so that inputs differing in a few bits give unrelated outputs
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,146 @@
+package placement
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKeys = 100000
+
+var strategies = []string{StrategyRing, StrategyRendezvous}
+
+func newMembers(count int) []Member {
+	members := make([]Member, 0, count)
+	for i := 1; i <= count; i++ {
+		members = append(members, Member{ID: fmt.Sprintf("node-%d", i), Weight: 1})
+	}
+	return members
+}
+
+func newPlacement(t *testing.T, strategy string, members []Member) Placement {
+	p, err := New(strategy, members, DefaultVirtualNodes)
+	assert.NoError(t, err)
+	return p
+}
+
+// assignments maps every test key to its primary member
+func assignments(p Placement) map[string]string {
+	owners := make(map[string]string, testKeys)
+	for i := 0; i < testKeys; i++ {
+		key := fmt.Sprintf("object%d", i)
+		owners[key] = p.Locate(key, 1)[0]
+	}
+	return owners
+}
+
+func countPerMember(owners map[string]string) map[string]int {
+	counts := make(map[string]int)
+	for _, member := range owners {
+		counts[member]++
+	}
+	return counts
+}
+
+func TestPlacementDistribution(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			members := newMembers(5)
+			counts := countPerMember(assignments(newPlacement(t, strategy, members)))
+
+			// Every member should get close to its fair share of 20%
+			expected := float64(testKeys) / float64(len(members))
+			for _, member := range members {
+				share := float64(counts[member.ID]) / expected
+				assert.InDelta(t, 1.0, share, 0.2, "member %s owns %d keys", member.ID, counts[member.ID])
+			}
+			t.Logf("keys per member: %v", counts)
+		})
+	}
+}
+
+func TestPlacementWeights(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			members := newMembers(3)
+			members[0].Weight = 2
+			counts := countPerMember(assignments(newPlacement(t, strategy, members)))
+
+			// The weighted member owns half of the keys, the others a quarter each
+			assert.InDelta(t, 0.5, float64(counts["node-1"])/testKeys, 0.05)
+			assert.InDelta(t, 0.25, float64(counts["node-2"])/testKeys, 0.05)
+			assert.InDelta(t, 0.25, float64(counts["node-3"])/testKeys, 0.05)
+		})
+	}
+}
+
+func TestPlacementMinimalMovementOnAdd(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			before := assignments(newPlacement(t, strategy, newMembers(3)))
+			after := assignments(newPlacement(t, strategy, newMembers(4)))
+
+			moved := 0
+			for key, owner := range before {
+				if after[key] != owner {
+					moved++
+					// Keys only ever move to the new member
+					assert.Equal(t, "node-4", after[key])
+				}
+			}
+
+			// About 1/4 of the keys move to the new member
+			assert.InDelta(t, 0.25, float64(moved)/testKeys, 0.05)
+			t.Logf("moved %d of %d keys", moved, testKeys)
+		})
+	}
+}
+
+func TestPlacementMinimalMovementOnRemove(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			before := assignments(newPlacement(t, strategy, newMembers(4)))
+			after := assignments(newPlacement(t, strategy, newMembers(3)))
+
+			for key, owner := range before {
+				// Only the keys of the removed member move
+				if owner != "node-4" {
+					assert.Equal(t, owner, after[key])
+				}
+			}
+		})
+	}
+}
+
+func TestPlacementLocate(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			p := newPlacement(t, strategy, newMembers(3))
+
+			for i := 0; i < 1000; i++ {
+				key := fmt.Sprintf("object%d", i)
+				owners := p.Locate(key, 3)
+				assert.ElementsMatch(t, []string{"node-1", "node-2", "node-3"}, owners)
+
+				// Asking for fewer replicas returns a prefix of the preference list
+				assert.Equal(t, owners[:2], p.Locate(key, 2))
+				assert.Equal(t, owners[:1], p.Locate(key, 1))
+			}
+
+			// More replicas than members returns every member once
+			assert.Len(t, p.Locate("object", 5), 3)
+			assert.Equal(t, 3, p.Members())
+
+			// Placements built from the same members agree
+			assert.Equal(t, p.Locate("object", 3), newPlacement(t, strategy, newMembers(3)).Locate("object", 3))
+
+			assert.Nil(t, newPlacement(t, strategy, nil).Locate("object", 1))
+		})
+	}
+}
+
+func TestNewUnknownStrategy(t *testing.T) {
+	_, err := New("modulo", newMembers(3), DefaultVirtualNodes)
+	assert.EqualError(t, err, `unknown placement strategy "modulo"`)
+}
@@ -0,0 +1,82 @@
+package placement
+
+import (
+	"math"
+	"sort"
+)
+
+// rendezvousMember is a member with its precomputed hash
+type rendezvousMember struct {
+	id     string
+	hash   uint64
+	weight float64
+}
+
+// Rendezvous implements highest random weight hashing. Every member scores every key and the
+// highest scoring members own it, so removing a member only moves the keys it owned and adding
+// one only takes keys from the others. Weights use the logarithmic method so a member's share of
+// the keys is proportional to its weight. A Rendezvous is immutable and safe for concurrent use.
+type Rendezvous struct {
+	members []rendezvousMember
+}
+
+// NewRendezvous builds a rendezvous placement for the members
+func NewRendezvous(members []Member) *Rendezvous {
+	r := &Rendezvous{}
+	seen := make(map[string]bool, len(members))
+	for _, member := range members {
+		if seen[member.ID] {
+			continue
+		}
+		seen[member.ID] = true
+		r.members = append(r.members, rendezvousMember{
+			id:     member.ID,
+			hash:   hashKey(member.ID),
+			weight: float64(member.weight()),
+		})
+	}
+	return r
+}
+
+// Locate returns up to n distinct member IDs responsible for key, in preference order
+func (r *Rendezvous) Locate(key string, n int) []string {
+	if len(r.members) == 0 || n < 1 {
+		return nil
+	}
+	n = min(n, len(r.members))
+
+	type scored struct {
+		id    string
+		score float64
+	}
+	keyHash := hashKey(key)
+	scores := make([]scored, 0, len(r.members))
+	for _, member := range r.members {
+		scores = append(scores, scored{id: member.id, score: member.score(keyHash)})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].score == scores[j].score {
+			return scores[i].id < scores[j].id
+		}
+		return scores[i].score > scores[j].score
+	})
+
+	owners := make([]string, 0, n)
+	for _, s := range scores[:n] {
+		owners = append(owners, s.id)
+	}
+	return owners
+}
+
+// Members returns the number of distinct members
+func (r *Rendezvous) Members() int {
+	return len(r.members)
+}
+
+// score combines the key and member hashes into the member's weighted score for the key
+func (m rendezvousMember) score(keyHash uint64) float64 {
+	h := mix64(keyHash ^ m.hash)
+	// Map the hash to a uniform value in (0, 1)
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return -m.weight / math.Log(u)
+}
@@ -0,0 +1,104 @@
+package placement
+
+import (
+	"sort"
+	"strconv"
+)
+
+// DefaultVirtualNodes is the number of points a member with weight 1 owns on the ring
+const DefaultVirtualNodes = 128
+
+// MaxWeight is the largest weight of a member. It bounds the points a single member adds to the
+// ring, which is rebuilt whenever the members change.
+const MaxWeight = 100
+
+// Member is a physical node taking part in placement
+type Member struct {
+	ID string
+	// Weight is the member's relative share of the keys, values below 1 count as 1 and values
+	// above MaxWeight as MaxWeight
+	Weight int
+}
+
+// weight returns the weight the member is placed with
+func (m Member) weight() int {
+	return min(max(m.Weight, 1), MaxWeight)
+}
+
+// vnode is a single point on the ring owned by a member
+type vnode struct {
+	hash   uint64
+	member string
+}
+
+// Ring is a consistent hash ring with virtual nodes. Every member owns a number of points
+// proportional to its weight and a key belongs to the first points clockwise from its hash,
+// so adding or removing a member only moves the keys of the points it gains or loses.
+// A Ring is immutable and safe for concurrent use.
+type Ring struct {
+	vnodes  []vnode
+	members int
+}
+
+// NewRing builds a ring for the members, placing virtualNodes points per unit of weight
+func NewRing(members []Member, virtualNodes int) *Ring {
+	if virtualNodes < 1 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	ring := &Ring{}
+	seen := make(map[string]bool, len(members))
+	for _, member := range members {
+		if seen[member.ID] {
+			continue
+		}
+		seen[member.ID] = true
+		ring.members++
+
+		for i := 0; i < virtualNodes*member.weight(); i++ {
+			ring.vnodes = append(ring.vnodes, vnode{
+				hash:   hashKey(member.ID + "#" + strconv.Itoa(i)),
+				member: member.ID,
+			})
+		}
+	}
+
+	sort.Slice(ring.vnodes, func(i, j int) bool {
+		if ring.vnodes[i].hash == ring.vnodes[j].hash {
+			// Break ties deterministically so every gateway builds the same ring
+			return ring.vnodes[i].member < ring.vnodes[j].member
+		}
+		return ring.vnodes[i].hash < ring.vnodes[j].hash
+	})
+
+	return ring
+}
+
+// Locate returns up to n distinct member IDs responsible for key, in preference order
+func (r *Ring) Locate(key string, n int) []string {
+	if len(r.vnodes) == 0 || n < 1 {
+		return nil
+	}
+	n = min(n, r.members)
+
+	// Find the first point clockwise from the key, wrapping around the end of the ring
+	h := hashKey(key)
+	start := sort.Search(len(r.vnodes), func(i int) bool { return r.vnodes[i].hash >= h })
+
+	owners := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+	for i := 0; i < len(r.vnodes) && len(owners) < n; i++ {
+		member := r.vnodes[(start+i)%len(r.vnodes)].member
+		if !seen[member] {
+			seen[member] = true
+			owners = append(owners, member)
+		}
+	}
+
+	return owners
+}
+
+// Members returns the number of distinct members on the ring
+func (r *Ring) Members() int {
+	return r.members
+}
@@ -0,0 +1,42 @@
+package placement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingVirtualNodes(t *testing.T) {
+	members := newMembers(3)
+	members[0].Weight = 2
+
+	ring := NewRing(members, 10)
+	assert.Len(t, ring.vnodes, 40)
+	assert.Equal(t, 3, ring.Members())
+
+	// Weights are capped so a single member cannot blow up the ring
+	ring = NewRing([]Member{{ID: "node-0", Weight: 65535}}, 10)
+	assert.Len(t, ring.vnodes, 10*MaxWeight)
+
+	// Duplicate members are only placed once and non-positive counts use the default
+	ring = NewRing(append(newMembers(2), Member{ID: "node-1"}), 0)
+	assert.Len(t, ring.vnodes, 2*DefaultVirtualNodes)
+	assert.Equal(t, 2, ring.Members())
+}
+
+func TestRingSpreadImprovesWithVirtualNodes(t *testing.T) {
+	members := newMembers(5)
+
+	// spread is the gap between the busiest and idlest member relative to a fair share
+	spread := func(virtualNodes int) float64 {
+		counts := countPerMember(assignments(NewRing(members, virtualNodes)))
+		lowest, highest := testKeys, 0
+		for _, member := range members {
+			lowest = min(lowest, counts[member.ID])
+			highest = max(highest, counts[member.ID])
+		}
+		return float64(highest-lowest) / (float64(testKeys) / float64(len(members)))
+	}
+
+	assert.Less(t, spread(DefaultVirtualNodes), spread(1))
+}
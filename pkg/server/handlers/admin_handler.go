@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+// HandleGetRebalance creates a handler for the GET /admin/rebalance endpoint reporting progress
+func HandleGetRebalance(rebalancer objectstorage.Rebalancer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, BuildResponse("success", "Rebalance status", rebalancer.Status()))
+	}
+}
+
+// HandleStartRebalance creates a handler for the POST /admin/rebalance endpoint.
+// The rebalance runs in the background, its progress is reported by HandleGetRebalance.
+func HandleStartRebalance(rebalancer objectstorage.Rebalancer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := rebalancer.Start(); err != nil {
+			abortWithError(c, err, "Failed to start rebalance")
+			return
+		}
+
+		c.JSON(http.StatusAccepted, BuildResponse("success", "Rebalance started", rebalancer.Status()))
+	}
+}
+
+// HandleStopRebalance creates a handler for the DELETE /admin/rebalance endpoint
+func HandleStopRebalance(rebalancer objectstorage.Rebalancer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rebalancer.Stop()
+		c.JSON(http.StatusOK, BuildResponse("success", "Rebalance stopped", rebalancer.Status()))
+	}
+}
+
+// HandleSetRebalanceThrottle creates a handler for the PUT /admin/rebalance/throttle endpoint
+func HandleSetRebalanceThrottle(rebalancer objectstorage.Rebalancer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var throttle objectstorage.RebalanceThrottle
+		if err := c.ShouldBindJSON(&throttle); err != nil {
+			abortWithError(c, &ValidationError{message: "Invalid throttle"}, "")
+			return
+		}
+
+		if err := rebalancer.SetThrottle(throttle); err != nil {
+			// The rebalancer only rejects throttles that are out of range
+			abortWithError(c, &ValidationError{message: err.Error()}, "")
+			return
+		}
+
+		c.JSON(http.StatusOK, BuildResponse("success", "Rebalance throttle updated", rebalancer.Status()))
+	}
+}
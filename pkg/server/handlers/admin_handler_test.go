@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage/fakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebalanceHandlers(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	status := objectstorage.RebalanceStatus{State: objectstorage.RebalanceRunning, Scanned: 10, Moved: 3, BytesMoved: 300}
+	statusJSON := `{"state":"running","scanned":10,"misplaced":0,"moved":3,"failed":0,"bytes_moved":300,"throttle":{"objects_per_second":0,"bytes_per_second":0}}`
+
+	rebalancerRunning := &fakes.InterfaceRebalancer{}
+	rebalancerRunning.StartReturns(objectstorage.ErrRebalanceRunning)
+	rebalancerRunning.StatusReturns(status)
+
+	rebalancerIdle := &fakes.InterfaceRebalancer{}
+	rebalancerIdle.StatusReturns(status)
+
+	rebalancerInvalidThrottle := &fakes.InterfaceRebalancer{}
+	rebalancerInvalidThrottle.SetThrottleReturns(errors.New("throttle limits must not be negative"))
+
+	// Test cases
+	testCases := []struct {
+		name             string
+		method           string
+		path             string
+		body             string
+		rebalancerFake   *fakes.InterfaceRebalancer
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:             "Status",
+			method:           http.MethodGet,
+			path:             "/admin/rebalance",
+			rebalancerFake:   rebalancerIdle,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"status":"success","message":"Rebalance status","data":` + statusJSON + `}`,
+		},
+		{
+			name:             "Start",
+			method:           http.MethodPost,
+			path:             "/admin/rebalance",
+			rebalancerFake:   rebalancerIdle,
+			expectedStatus:   http.StatusAccepted,
+			expectedResponse: `{"status":"success","message":"Rebalance started","data":` + statusJSON + `}`,
+		},
+		{
+			name:             "Start While Running",
+			method:           http.MethodPost,
+			path:             "/admin/rebalance",
+			rebalancerFake:   rebalancerRunning,
+			expectedStatus:   http.StatusConflict,
+			expectedResponse: `{"status":"error","code":"conflict","message":"rebalance already running"}`,
+		},
+		{
+			name:             "Stop",
+			method:           http.MethodDelete,
+			path:             "/admin/rebalance",
+			rebalancerFake:   rebalancerIdle,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"status":"success","message":"Rebalance stopped","data":` + statusJSON + `}`,
+		},
+		{
+			name:             "Set Throttle",
+			method:           http.MethodPut,
+			path:             "/admin/rebalance/throttle",
+			body:             `{"objects_per_second":5,"bytes_per_second":1048576}`,
+			rebalancerFake:   rebalancerIdle,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"status":"success","message":"Rebalance throttle updated","data":` + statusJSON + `}`,
+		},
+		{
+			name:             "Malformed Throttle",
+			method:           http.MethodPut,
+			path:             "/admin/rebalance/throttle",
+			body:             `{"objects_per_second":"fast"}`,
+			rebalancerFake:   rebalancerIdle,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"status":"error","code":"invalid_request","message":"Invalid throttle"}`,
+		},
+		{
+			name:             "Negative Throttle",
+			method:           http.MethodPut,
+			path:             "/admin/rebalance/throttle",
+			body:             `{"objects_per_second":-1}`,
+			rebalancerFake:   rebalancerInvalidThrottle,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"status":"error","code":"invalid_request","message":"throttle limits must not be negative"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.GET("/admin/rebalance", HandleGetRebalance(tc.rebalancerFake))
+			router.POST("/admin/rebalance", HandleStartRebalance(tc.rebalancerFake))
+			router.DELETE("/admin/rebalance", HandleStopRebalance(tc.rebalancerFake))
+			router.PUT("/admin/rebalance/throttle", HandleSetRebalanceThrottle(tc.rebalancerFake))
+
+			req, _ := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			assert.JSONEq(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+
+	// The parsed throttle is passed on
+	assert.Equal(t, objectstorage.RebalanceThrottle{ObjectsPerSecond: 5, BytesPerSecond: 1048576}, rebalancerIdle.SetThrottleArgsForCall(0))
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+// checkReadPreconditions evaluates the conditional headers of a GET or HEAD request against the
+// stored object following RFC 7232 section 6. It returns 0 when the request should proceed,
+// otherwise the status code to respond with.
+func checkReadPreconditions(c *gin.Context, info objectstorage.ObjectInfo) int {
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		if !etagListMatches(ifMatch, info.ETag, false) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, ok := parseHTTPDate(c.GetHeader("If-Unmodified-Since")); ok {
+		if info.LastModified.Truncate(time.Second).After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if etagListMatches(ifNoneMatch, info.ETag, true) {
+			return http.StatusNotModified
+		}
+	} else if since, ok := parseHTTPDate(c.GetHeader("If-Modified-Since")); ok {
+		if !info.LastModified.Truncate(time.Second).After(since) {
+			return http.StatusNotModified
+		}
+	}
+
+	return 0
+}
+
+// rangeStillValid reports whether the Range header applies given the If-Range header,
+// which names the ETag or modification date the client's partial copy came from
+func rangeStillValid(c *gin.Context, info objectstorage.ObjectInfo) bool {
+	ifRange := c.GetHeader("If-Range")
+	if ifRange == "" {
+		return true
+	}
+	if date, ok := parseHTTPDate(ifRange); ok {
+		return info.LastModified.Truncate(time.Second).Equal(date)
+	}
+	return etagListMatches(ifRange, info.ETag, false) && !strings.HasPrefix(ifRange, "W/")
+}
+
+// respondPreconditionStatus answers a read whose preconditions stopped it with 304 or 412
+func respondPreconditionStatus(c *gin.Context, status int, info objectstorage.ObjectInfo) {
+	if status == http.StatusNotModified {
+		// A 304 still describes the current representation
+		if info.ETag != "" {
+			c.Writer.Header().Set("ETag", `"`+info.ETag+`"`)
+		}
+		if !info.LastModified.IsZero() {
+			c.Writer.Header().Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+		}
+		c.Status(http.StatusNotModified)
+		return
+	}
+	abortWithError(c, objectstorage.ErrPreconditionFailed, "")
+}
+
+// errMultipleEntityTags is returned for conditional uploads naming more than one ETag
+var errMultipleEntityTags = &ValidationError{message: "conditional uploads support a single entity tag"}
+
+// setPutConditions copies the If-Match and If-None-Match headers of an upload into opts
+func setPutConditions(c *gin.Context, opts *objectstorage.PutOptions) error {
+	for header, target := range map[string]*string{
+		"If-Match":      &opts.IfMatch,
+		"If-None-Match": &opts.IfNoneMatch,
+	} {
+		tags := parseEntityTags(c.GetHeader(header))
+		if len(tags) > 1 {
+			return errMultipleEntityTags
+		}
+		if len(tags) == 1 {
+			*target = strings.Trim(tags[0], `"`)
+		}
+	}
+	return nil
+}
+
+// parseEntityTags splits an If-Match or If-None-Match header into its entity tags
+func parseEntityTags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// etagListMatches reports whether the header list matches the object ETag.
+// Weak comparison ignores the W/ prefix, strong comparison never matches weak tags.
+func etagListMatches(header string, etag string, weak bool) bool {
+	for _, tag := range parseEntityTags(header) {
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if strings.Trim(tag, `"`) == etag && etag != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHTTPDate parses an HTTP date header, reporting false if it is missing or malformed
+func parseHTTPDate(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage/fakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleGetObjectConditional(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	modified := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	info := objectstorage.ObjectInfo{ID: "testobject", Size: 12, ETag: "abc123", LastModified: modified}
+
+	objectStorageFake := &fakes.InterfaceObjectStorage{}
+	objectStorageFake.StatObjectReturns(info, nil)
+	objectStorageFake.GetObjectReturns(newMockReadCloser("test content"), info, nil)
+
+	// Test cases
+	testCases := []struct {
+		name           string
+		method         string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{name: "If-None-Match Matches", method: http.MethodGet, headers: map[string]string{"If-None-Match": `"abc123"`}, expectedStatus: http.StatusNotModified},
+		{name: "If-None-Match Weak Matches", method: http.MethodGet, headers: map[string]string{"If-None-Match": `"other", W/"abc123"`}, expectedStatus: http.StatusNotModified},
+		{name: "If-None-Match Differs", method: http.MethodGet, headers: map[string]string{"If-None-Match": `"other"`}, expectedStatus: http.StatusOK},
+		{name: "If-Modified-Since Not Modified", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, expectedStatus: http.StatusNotModified},
+		{name: "If-Modified-Since Modified", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, expectedStatus: http.StatusOK},
+		{
+			name:           "If-None-Match Takes Precedence",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": modified.Format(http.TimeFormat)},
+			expectedStatus: http.StatusOK,
+		},
+		{name: "If-Match Differs", method: http.MethodGet, headers: map[string]string{"If-Match": `"other"`}, expectedStatus: http.StatusPreconditionFailed},
+		{name: "If-Match Matches", method: http.MethodGet, headers: map[string]string{"If-Match": `"abc123"`}, expectedStatus: http.StatusOK},
+		{name: "If-Unmodified-Since Modified", method: http.MethodGet, headers: map[string]string{"If-Unmodified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, expectedStatus: http.StatusPreconditionFailed},
+		{name: "HEAD Not Modified", method: http.MethodHead, headers: map[string]string{"If-None-Match": "*"}, expectedStatus: http.StatusNotModified},
+		{name: "Range With Matching If-Range", method: http.MethodGet, headers: map[string]string{"Range": "bytes=0-3", "If-Range": `"abc123"`}, expectedStatus: http.StatusPartialContent},
+		{name: "Range With Stale If-Range", method: http.MethodGet, headers: map[string]string{"Range": "bytes=0-3", "If-Range": `"other"`}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.GET("/object/:id", HandleGetObject(objectStorageFake))
+			router.HEAD("/object/:id", HandleHeadObject(objectStorageFake))
+
+			req, _ := http.NewRequest(tc.method, "/object/testobject", nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			if tc.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, resp.Body.String())
+				assert.Equal(t, `"abc123"`, resp.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestHandlePutObjectConditional(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	objectStorageConflict := &fakes.InterfaceObjectStorage{}
+	objectStorageConflict.PutObjectReturns(objectstorage.ErrPreconditionFailed)
+
+	// Test cases
+	testCases := []struct {
+		name              string
+		headers           map[string]string
+		objectStorageFake *fakes.InterfaceObjectStorage
+		expectedStatus    int
+		expectedResponse  string
+		expectedIfMatch   string
+		expectedNoneMatch string
+	}{
+		{
+			name:              "Create Only",
+			headers:           map[string]string{"If-None-Match": "*"},
+			objectStorageFake: &fakes.InterfaceObjectStorage{},
+			expectedStatus:    http.StatusCreated,
+			expectedResponse:  `{"message":"Object testobject stored successfully","status":"success"}`,
+			expectedNoneMatch: "*",
+		},
+		{
+			name:              "Optimistic Concurrency",
+			headers:           map[string]string{"If-Match": `"abc123"`},
+			objectStorageFake: &fakes.InterfaceObjectStorage{},
+			expectedStatus:    http.StatusCreated,
+			expectedResponse:  `{"message":"Object testobject stored successfully","status":"success"}`,
+			expectedIfMatch:   "abc123",
+		},
+		{
+			name:              "Conflict",
+			headers:           map[string]string{"If-Match": `"stale"`},
+			objectStorageFake: objectStorageConflict,
+			expectedStatus:    http.StatusPreconditionFailed,
+			expectedResponse:  `{"code":"precondition_failed","message":"Precondition failed","status":"error"}`,
+			expectedIfMatch:   "stale",
+		},
+		{
+			name:              "Multiple Entity Tags",
+			headers:           map[string]string{"If-Match": `"a", "b"`},
+			objectStorageFake: &fakes.InterfaceObjectStorage{},
+			expectedStatus:    http.StatusBadRequest,
+			expectedResponse:  `{"code":"invalid_request","message":"conditional uploads support a single entity tag","status":"error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.PUT("/object/:id", HandlePutObject(tc.objectStorageFake))
+
+			req, _ := http.NewRequest(http.MethodPut, "/object/testobject", strings.NewReader("test content"))
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			assert.JSONEq(t, tc.expectedResponse, resp.Body.String())
+			if tc.objectStorageFake.PutObjectCallCount() > 0 {
+				_, _, _, _, opts := tc.objectStorageFake.PutObjectArgsForCall(0)
+				assert.Equal(t, tc.expectedIfMatch, opts.IfMatch)
+				assert.Equal(t, tc.expectedNoneMatch, opts.IfNoneMatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+// HandleDeleteObject creates a handler for the DELETE /object/{id} endpoint.
+// Deletes are idempotent: removing an object that does not exist still succeeds.
+func HandleDeleteObject(storageService objectstorage.ObjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		objectID := c.Param("id")
+
+		// Delete the object
+		err := storageService.DeleteObject(c, objectID)
+		if err != nil {
+			abortWithError(c, err, "Failed to delete object")
+			return
+		}
+
+		c.JSON(http.StatusOK, BuildResponse("success", fmt.Sprintf("Object %s deleted successfully", objectID), nil))
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage/fakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleDeleteObject(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	objectStorageFailure1 := &fakes.InterfaceObjectStorage{}
+	objectStorageFailure1.DeleteObjectReturns(objectstorage.NewError(objectstorage.ErrInvalidID, "object ID must contain only alphanumeric characters", nil))
+
+	objectStorageFailure2 := &fakes.InterfaceObjectStorage{}
+	objectStorageFailure2.DeleteObjectReturns(objectstorage.NewError(objectstorage.ErrInvalidID, "object ID must be between 1 and 32 characters", nil))
+
+	objectStorageFailure3 := &fakes.InterfaceObjectStorage{}
+	objectStorageFailure3.DeleteObjectReturns(errors.New("failed to delete object: connection refused"))
+
+	objectStorageSuccess := &fakes.InterfaceObjectStorage{}
+	objectStorageSuccess.DeleteObjectReturns(nil)
+
+	// Test cases
+	testCases := []struct {
+		name              string
+		objectID          string
+		objectStorageFake *fakes.InterfaceObjectStorage
+		expectedStatus    int
+		expectedResponse  string
+	}{
+		{
+			name:              "Success",
+			objectID:          "testobject",
+			objectStorageFake: objectStorageSuccess,
+			expectedStatus:    http.StatusOK,
+			expectedResponse:  `{"message":"Object testobject deleted successfully","status":"success"}`,
+		},
+		{
+			name:              "Invalid ObjectID Characters",
+			objectID:          "test-object!",
+			objectStorageFake: objectStorageFailure1,
+			expectedStatus:    http.StatusBadRequest,
+			expectedResponse:  `{"status":"error","code":"invalid_id","message":"object ID must contain only alphanumeric characters"}`,
+		},
+		{
+			name:              "Invalid ObjectID Length",
+			objectID:          "testobjectthatiswaytoolongforthelimitsofthesystem",
+			objectStorageFake: objectStorageFailure2,
+			expectedStatus:    http.StatusBadRequest,
+			expectedResponse:  `{"status":"error","code":"invalid_id","message":"object ID must be between 1 and 32 characters"}`,
+		},
+		{
+			name:              "Backend Failure",
+			objectID:          "testobject",
+			objectStorageFake: objectStorageFailure3,
+			expectedStatus:    http.StatusInternalServerError,
+			expectedResponse:  `{"status":"error","code":"internal_error","message":"Failed to delete object"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gin router
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.DELETE("/object/:id", HandleDeleteObject(tc.objectStorageFake))
+
+			// Create a test request
+			req, _ := http.NewRequest(http.MethodDelete, "/object/"+tc.objectID, nil)
+			resp := httptest.NewRecorder()
+
+			// Serve the request
+			router.ServeHTTP(resp, req)
+
+			// Check status code and body
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			assert.JSONEq(t, tc.expectedResponse, resp.Body.String())
+			assert.Equal(t, 1, tc.objectStorageFake.DeleteObjectCallCount())
+		})
+	}
+}
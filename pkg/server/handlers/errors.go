@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+// ValidationError represents an error that occurs during validation of a request
+type ValidationError struct {
+	message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.message
+}
+
+// errorKinds maps the kinds of errors to their status and machine-readable code.
+// An empty message sends the error itself, so only kinds with messages fit for clients leave it out.
+var errorKinds = []struct {
+	kind    error
+	status  int
+	code    string
+	message string
+}{
+	{objectstorage.ErrInvalidID, http.StatusBadRequest, "invalid_id", ""},
+	{objectstorage.ErrInvalidListOptions, http.StatusBadRequest, "invalid_request", ""},
+	{objectstorage.ErrNotFound, http.StatusNotFound, "not_found", "Object not found"},
+	{objectstorage.ErrConflict, http.StatusConflict, "conflict", ""},
+	{objectstorage.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed", "Precondition failed"},
+	{objectstorage.ErrTooLarge, http.StatusRequestEntityTooLarge, "too_large", "Object too large"},
+	{objectstorage.ErrInvalidRange, http.StatusRequestedRangeNotSatisfiable, "range_not_satisfiable", "Requested range not satisfiable"},
+	{errNoOverlap, http.StatusRequestedRangeNotSatisfiable, "range_not_satisfiable", "Requested range not satisfiable"},
+	{objectstorage.ErrUnavailable, http.StatusServiceUnavailable, "unavailable", "Storage node unavailable"},
+}
+
+// abortWithError stops the request with err, which ErrorHandler turns into the response.
+// message is sent instead of the details of errors that are not of a known kind.
+func abortWithError(c *gin.Context, err error, message string) {
+	c.Error(err).SetMeta(message)
+	c.Abort()
+}
+
+// ErrorHandler responds to requests aborted with abortWithError. The status code and the
+// machine-readable error code are chosen by the kind of the error, unknown errors are 500s.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		// Responses that were already sent, such as after a panic, are left alone
+		if !c.IsAborted() || c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+
+		last := c.Errors.Last()
+		status, code, message := mapError(last.Err)
+		if status == http.StatusInternalServerError {
+			if meta, ok := last.Meta.(string); ok && meta != "" {
+				message = meta
+			}
+		}
+
+		// Nodes behind an open circuit breaker report when they will be tried again
+		var unavailable *objectstorage.NodeUnavailableError
+		if errors.As(last.Err, &unavailable) {
+			c.Header("Retry-After", strconv.Itoa(unavailable.RetryAfterSeconds()))
+		}
+
+		writeError(c, status, code, message)
+	}
+}
+
+// mapError returns the status, error code and message of the response for err
+func mapError(err error) (int, string, string) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, "invalid_request", validationErr.Error()
+	}
+
+	for _, k := range errorKinds {
+		if !errors.Is(err, k.kind) {
+			continue
+		}
+		if k.message == "" {
+			return k.status, k.code, err.Error()
+		}
+		return k.status, k.code, k.message
+	}
+
+	return http.StatusInternalServerError, "internal_error", "Internal server error"
+}
+
+// writeError sends an error response carrying the error code and the request ID.
+// HEAD responses carry no body, so only the status code is sent.
+func writeError(c *gin.Context, status int, code string, message string) {
+	if c.Request.Method == http.MethodHead {
+		c.Status(status)
+		return
+	}
+
+	response := BuildResponse("error", message, nil)
+	response["code"] = code
+	if requestID := GetRequestID(c); requestID != "" {
+		response["request_id"] = requestID
+	}
+	c.JSON(status, response)
+}
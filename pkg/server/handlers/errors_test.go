@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHandler(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name             string
+		method           string
+		err              error
+		message          string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:             "Wrapped Kind",
+			method:           http.MethodGet,
+			err:              fmt.Errorf("stat: %w", objectstorage.ErrNotFound),
+			message:          "Failed to retrieve object",
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"status":"error","code":"not_found","message":"Object not found","request_id":"req-1"}`,
+		},
+		{
+			name:             "Too Large",
+			method:           http.MethodPut,
+			err:              objectstorage.NewError(objectstorage.ErrTooLarge, "", errors.New("EntityTooLarge")),
+			message:          "Failed to store object",
+			expectedStatus:   http.StatusRequestEntityTooLarge,
+			expectedResponse: `{"status":"error","code":"too_large","message":"Object too large","request_id":"req-1"}`,
+		},
+		{
+			name:             "Range Not Satisfiable",
+			method:           http.MethodGet,
+			err:              objectstorage.NewError(objectstorage.ErrInvalidRange, "invalid range: 10-12 of 10 bytes", nil),
+			message:          "Failed to retrieve object",
+			expectedStatus:   http.StatusRequestedRangeNotSatisfiable,
+			expectedResponse: `{"status":"error","code":"range_not_satisfiable","message":"Requested range not satisfiable","request_id":"req-1"}`,
+		},
+		{
+			name:             "Unknown Error",
+			method:           http.MethodGet,
+			err:              errors.New("connection reset by peer"),
+			message:          "Failed to retrieve object",
+			expectedStatus:   http.StatusInternalServerError,
+			expectedResponse: `{"status":"error","code":"internal_error","message":"Failed to retrieve object","request_id":"req-1"}`,
+		},
+		{
+			name:           "Head Without Body",
+			method:         http.MethodHead,
+			err:            objectstorage.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(SetRequestID())
+			router.Use(ErrorHandler())
+			router.Handle(tc.method, "/object/:id", func(c *gin.Context) {
+				abortWithError(c, tc.err, tc.message)
+			})
+
+			req, _ := http.NewRequest(tc.method, "/object/testobject", nil)
+			req.Header.Set("X-Request-ID", "req-1")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			if tc.expectedResponse == "" {
+				assert.Empty(t, resp.Body.String())
+			} else {
+				assert.JSONEq(t, tc.expectedResponse, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestErrorHandlerKeepsWrittenResponse(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/object/:id", func(c *gin.Context) {
+		// The body was already being streamed when the copy failed
+		c.String(http.StatusOK, "partial")
+		abortWithError(c, errors.New("broken pipe"), "Failed to send object")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/object/testobject", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "partial", resp.Body.String())
+}
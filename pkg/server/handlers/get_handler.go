@@ -3,7 +3,10 @@ package handlers
 import (
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
@@ -12,37 +15,114 @@ import (
 func HandleGetObject(storageService objectstorage.ObjectStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		objectID := c.Param("id")
+		c.Writer.Header().Set("Accept-Ranges", "bytes")
+
+		if c.GetHeader("Range") != "" && serveObjectRanges(c, storageService, objectID) {
+			return
+		}
 
 		// Retrieve the object
-		obj, err := storageService.GetObject(c, objectID)
+		obj, info, err := storageService.GetObject(c, objectID, objectstorage.GetOptions{})
 		if err != nil {
-			c.Error(err)
+			abortWithError(c, err, "Failed to retrieve object")
+			return
+		}
+		defer obj.Close()
 
-			if fmt.Sprintf("%v", err) == "object ID must contain only alphanumeric characters" ||
-				fmt.Sprintf("%v", err) == "object ID must be between 1 and 32 characters" {
-				c.JSON(http.StatusBadRequest, BuildResponse("error", err.Error(), nil))
-				return
-			}
+		if status := checkReadPreconditions(c, info); status != 0 {
+			respondPreconditionStatus(c, status, info)
+			return
+		}
 
-			if fmt.Sprintf("%v", err) == "object not found" {
-				c.JSON(http.StatusNotFound, BuildResponse("error", "Object not found", nil))
-				return
-			}
+		// Set content type and the remaining headers based on object metadata
+		setObjectHeaders(c, info)
 
-			c.JSON(http.StatusInternalServerError, BuildResponse("error", "Failed to retrieve object", nil))
+		// Copy the object to the response
+		_, err = io.Copy(c.Writer, obj)
+		if err != nil {
+			abortWithError(c, err, "Failed to send object")
 			return
 		}
+	}
+}
+
+// serveObjectRanges serves the byte ranges selected by the Range header with 206 Partial Content.
+// It returns false without writing a response when the whole object should be sent instead.
+func serveObjectRanges(c *gin.Context, storageService objectstorage.ObjectStorage, objectID string) bool {
+	// The object size is needed to resolve suffix and open-ended ranges
+	info, err := storageService.StatObject(c, objectID)
+	if err != nil {
+		abortWithError(c, err, "Failed to retrieve object")
+		return true
+	}
+
+	if status := checkReadPreconditions(c, info); status != 0 {
+		respondPreconditionStatus(c, status, info)
+		return true
+	}
+
+	// A stale If-Range means the client's partial copy is outdated and needs the whole object
+	if !rangeStillValid(c, info) {
+		return false
+	}
+
+	ranges, err := parseRange(c.GetHeader("Range"), info.Size)
+	if err != nil {
+		c.Writer.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+		abortWithError(c, err, "")
+		return true
+	}
+	if ranges == nil {
+		return false
+	}
+
+	setObjectHeaders(c, info)
+
+	if len(ranges) == 1 {
+		r := ranges[0]
+		obj, _, err := storageService.GetObject(c, objectID, objectstorage.GetOptions{Range: &r})
+		if err != nil {
+			abortWithError(c, err, "Failed to retrieve object")
+			return true
+		}
 		defer obj.Close()
 
-		// Set content type based on object metadata
-		c.Writer.Header().Set("Content-Type", "application/octet-stream")
+		c.Writer.Header().Set("Content-Range", contentRange(r, info.Size))
+		c.Writer.Header().Set("Content-Length", strconv.FormatInt(r.Length(), 10))
+		c.Status(http.StatusPartialContent)
+		if _, err := io.Copy(c.Writer, obj); err != nil {
+			c.Error(err)
+		}
+		return true
+	}
 
-		// Copy the object to the response
-		_, err = io.Copy(c.Writer, obj)
+	// Multiple ranges are sent as a multipart/byteranges body
+	contentType := c.Writer.Header().Get("Content-Type")
+	mw := multipart.NewWriter(c.Writer)
+	c.Writer.Header().Set("Content-Type", "multipart/byteranges; boundary="+mw.Boundary())
+	c.Status(http.StatusPartialContent)
+
+	for _, r := range ranges {
+		obj, _, err := storageService.GetObject(c, objectID, objectstorage.GetOptions{Range: &r})
 		if err != nil {
+			// The status line has already been sent, so the response can only be cut short
 			c.Error(err)
-			c.JSON(http.StatusInternalServerError, BuildResponse("error", "Failed to send object", nil))
-			return
+			return true
+		}
+
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":  {contentType},
+			"Content-Range": {contentRange(r, info.Size)},
+		})
+		if err == nil {
+			_, err = io.Copy(part, obj)
+		}
+		obj.Close()
+		if err != nil {
+			c.Error(err)
+			return true
 		}
 	}
+	mw.Close()
+	return true
 }
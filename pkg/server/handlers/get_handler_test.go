@@ -2,15 +2,18 @@ package handlers
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
 	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage/fakes"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,16 +35,19 @@ func TestHandleGetObject(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	objectStorageFailure1 := &fakes.InterfaceObjectStorage{}
-	objectStorageFailure1.GetObjectReturns(nil, fmt.Errorf("object ID must contain only alphanumeric characters"))
+	objectStorageFailure1.GetObjectReturns(nil, objectstorage.ObjectInfo{}, objectstorage.NewError(objectstorage.ErrInvalidID, "object ID must contain only alphanumeric characters", nil))
 
 	objectStorageFailure2 := &fakes.InterfaceObjectStorage{}
-	objectStorageFailure2.GetObjectReturns(nil, errors.New("object ID must be between 1 and 32 characters"))
+	objectStorageFailure2.GetObjectReturns(nil, objectstorage.ObjectInfo{}, objectstorage.NewError(objectstorage.ErrInvalidID, "object ID must be between 1 and 32 characters", nil))
 
 	objectStorageFailure3 := &fakes.InterfaceObjectStorage{}
-	objectStorageFailure3.GetObjectReturns(nil, errors.New("object not found"))
+	objectStorageFailure3.GetObjectReturns(nil, objectstorage.ObjectInfo{}, objectstorage.ErrNotFound)
+
+	objectStorageUnavailable := &fakes.InterfaceObjectStorage{}
+	objectStorageUnavailable.GetObjectReturns(nil, objectstorage.ObjectInfo{}, fmt.Errorf("quorum not reached: %w", &objectstorage.NodeUnavailableError{Node: "node-1", RetryAfter: 12500 * time.Millisecond}))
 
 	objectStorageSuccess := &fakes.InterfaceObjectStorage{}
-	objectStorageSuccess.GetObjectReturns(newMockReadCloser("test content"), nil)
+	objectStorageSuccess.GetObjectReturns(newMockReadCloser("test content"), objectstorage.ObjectInfo{}, nil)
 
 	// Test cases
 	testCases := []struct {
@@ -51,6 +57,7 @@ func TestHandleGetObject(t *testing.T) {
 		expectedStatus    int
 		expectedResponse  string
 		checkBody         bool
+		expectedRetry     string
 	}{
 		{
 			name:              "Success",
@@ -65,7 +72,7 @@ func TestHandleGetObject(t *testing.T) {
 			objectID:          "test-object!",
 			objectStorageFake: objectStorageFailure1,
 			expectedStatus:    http.StatusBadRequest,
-			expectedResponse:  `{"status":"error","message":"object ID must contain only alphanumeric characters"}`,
+			expectedResponse:  `{"status":"error","code":"invalid_id","message":"object ID must contain only alphanumeric characters"}`,
 			checkBody:         true,
 		},
 		{
@@ -73,7 +80,7 @@ func TestHandleGetObject(t *testing.T) {
 			objectID:          "testobjectthatiswaytoolongforthelimitsofthesystem",
 			objectStorageFake: objectStorageFailure2,
 			expectedStatus:    http.StatusBadRequest,
-			expectedResponse:  `{"status":"error","message":"object ID must be between 1 and 32 characters"}`,
+			expectedResponse:  `{"status":"error","code":"invalid_id","message":"object ID must be between 1 and 32 characters"}`,
 			checkBody:         true,
 		},
 		{
@@ -81,15 +88,25 @@ func TestHandleGetObject(t *testing.T) {
 			objectID:          "nonexistent",
 			objectStorageFake: objectStorageFailure3,
 			expectedStatus:    http.StatusNotFound,
-			expectedResponse:  `{"status":"error","message":"Object not found"}`,
+			expectedResponse:  `{"status":"error","code":"not_found","message":"Object not found"}`,
 			checkBody:         true,
 		},
+		{
+			name:              "Node Unavailable",
+			objectID:          "testobject",
+			objectStorageFake: objectStorageUnavailable,
+			expectedStatus:    http.StatusServiceUnavailable,
+			expectedResponse:  `{"status":"error","code":"unavailable","message":"Storage node unavailable"}`,
+			checkBody:         true,
+			expectedRetry:     "13",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Create a new Gin router
 			router := gin.New()
+			router.Use(ErrorHandler())
 
 			// Make sure we're using the correct fake for each test case
 			router.GET("/object/:id", HandleGetObject(tc.objectStorageFake))
@@ -103,6 +120,7 @@ func TestHandleGetObject(t *testing.T) {
 
 			// Check status code
 			assert.Equal(t, tc.expectedStatus, resp.Code)
+			assert.Equal(t, tc.expectedRetry, resp.Header().Get("Retry-After"))
 
 			// Check response body if needed
 			if tc.checkBody {
@@ -117,3 +135,143 @@ func TestHandleGetObject(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleGetObjectMetadata(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	objectStorageFake := &fakes.InterfaceObjectStorage{}
+	objectStorageFake.GetObjectReturns(newMockReadCloser("<html></html>"), objectstorage.ObjectInfo{
+		ID:                 "page",
+		Size:               13,
+		ETag:               "abc123",
+		ContentType:        "text/html",
+		ContentDisposition: `inline; filename="page.html"`,
+		CacheControl:       "max-age=60",
+		UserMetadata:       map[string]string{"Owner": "alice"},
+	}, nil)
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/object/:id", HandleGetObject(objectStorageFake))
+
+	req, _ := http.NewRequest(http.MethodGet, "/object/page", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "<html></html>", resp.Body.String())
+	assert.Equal(t, "text/html", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `inline; filename="page.html"`, resp.Header().Get("Content-Disposition"))
+	assert.Equal(t, "max-age=60", resp.Header().Get("Cache-Control"))
+	assert.Equal(t, "alice", resp.Header().Get("X-Object-Meta-Owner"))
+	assert.Equal(t, `"abc123"`, resp.Header().Get("ETag"))
+}
+
+func TestHandleGetObjectRange(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	const content = "0123456789"
+
+	// The fake serves the requested slice of content, like a real backend would
+	objectStorageFake := &fakes.InterfaceObjectStorage{}
+	objectStorageFake.StatObjectReturns(objectstorage.ObjectInfo{ID: "digits", Size: int64(len(content)), ContentType: "text/plain"}, nil)
+	objectStorageFake.GetObjectCalls(func(_ *gin.Context, _ string, opts objectstorage.GetOptions) (io.ReadCloser, objectstorage.ObjectInfo, error) {
+		data := content
+		if opts.Range != nil {
+			data = content[opts.Range.Start : opts.Range.End+1]
+		}
+		return newMockReadCloser(data), objectstorage.ObjectInfo{ID: "digits", Size: int64(len(data)), ContentType: "text/plain"}, nil
+	})
+
+	// Test cases
+	testCases := []struct {
+		name                 string
+		rangeHeader          string
+		expectedStatus       int
+		expectedBody         string
+		expectedContentRange string
+	}{
+		{
+			name:                 "Single Range",
+			rangeHeader:          "bytes=2-4",
+			expectedStatus:       http.StatusPartialContent,
+			expectedBody:         "234",
+			expectedContentRange: "bytes 2-4/10",
+		},
+		{
+			name:                 "Suffix Range",
+			rangeHeader:          "bytes=-3",
+			expectedStatus:       http.StatusPartialContent,
+			expectedBody:         "789",
+			expectedContentRange: "bytes 7-9/10",
+		},
+		{
+			name:                 "Unsatisfiable Range",
+			rangeHeader:          "bytes=20-",
+			expectedStatus:       http.StatusRequestedRangeNotSatisfiable,
+			expectedBody:         `{"code":"range_not_satisfiable","message":"Requested range not satisfiable","status":"error"}`,
+			expectedContentRange: "bytes */10",
+		},
+		{
+			name:           "Malformed Range Ignored",
+			rangeHeader:    "bytes=5-2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+		{
+			name:           "Ignored Range Unit",
+			rangeHeader:    "items=0-1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.GET("/object/:id", HandleGetObject(objectStorageFake))
+
+			req, _ := http.NewRequest(http.MethodGet, "/object/digits", nil)
+			req.Header.Set("Range", tc.rangeHeader)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			assert.Equal(t, tc.expectedBody, resp.Body.String())
+			assert.Equal(t, tc.expectedContentRange, resp.Header().Get("Content-Range"))
+		})
+	}
+
+	t.Run("Multiple Ranges", func(t *testing.T) {
+		router := gin.New()
+		router.Use(ErrorHandler())
+		router.GET("/object/:id", HandleGetObject(objectStorageFake))
+
+		req, _ := http.NewRequest(http.MethodGet, "/object/digits", nil)
+		req.Header.Set("Range", "bytes=0-1,8-")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusPartialContent, resp.Code)
+		mediaType, params, err := mime.ParseMediaType(resp.Header().Get("Content-Type"))
+		assert.NoError(t, err)
+		assert.Equal(t, "multipart/byteranges", mediaType)
+
+		reader := multipart.NewReader(resp.Body, params["boundary"])
+		var parts []string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			body, _ := io.ReadAll(part)
+			assert.Equal(t, "text/plain", part.Header.Get("Content-Type"))
+			parts = append(parts, part.Header.Get("Content-Range")+" "+string(body))
+		}
+		assert.Equal(t, []string{"bytes 0-1/10 01", "bytes 8-9/10 89"}, parts)
+	})
+}
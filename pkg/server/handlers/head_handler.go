@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+// HandleHeadObject creates a handler for the HEAD /object/{id} endpoint.
+// It reports the object's size, ETag and modification time without sending the content.
+func HandleHeadObject(storageService objectstorage.ObjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		objectID := c.Param("id")
+
+		// Retrieve the object metadata
+		info, err := storageService.StatObject(c, objectID)
+		if err != nil {
+			abortWithError(c, err, "Failed to retrieve object info")
+			return
+		}
+
+		if status := checkReadPreconditions(c, info); status != 0 {
+			respondPreconditionStatus(c, status, info)
+			return
+		}
+
+		setObjectHeaders(c, info)
+		c.Writer.Header().Set("Accept-Ranges", "bytes")
+		c.Writer.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+		c.Status(http.StatusOK)
+	}
+}
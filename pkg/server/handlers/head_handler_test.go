@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage/fakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleHeadObject(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	objectStorageFailure1 := &fakes.InterfaceObjectStorage{}
+	objectStorageFailure1.StatObjectReturns(objectstorage.ObjectInfo{}, objectstorage.NewError(objectstorage.ErrInvalidID, "object ID must contain only alphanumeric characters", nil))
+
+	objectStorageFailure2 := &fakes.InterfaceObjectStorage{}
+	objectStorageFailure2.StatObjectReturns(objectstorage.ObjectInfo{}, objectstorage.ErrNotFound)
+
+	objectStorageFailure3 := &fakes.InterfaceObjectStorage{}
+	objectStorageFailure3.StatObjectReturns(objectstorage.ObjectInfo{}, errors.New("failed to stat object: timeout"))
+
+	objectStorageSuccess := &fakes.InterfaceObjectStorage{}
+	objectStorageSuccess.StatObjectReturns(objectstorage.ObjectInfo{
+		ID:           "testobject",
+		Size:         12,
+		ETag:         "9a0364b9e99bb480dd25e1f0284c8555",
+		LastModified: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil)
+
+	// Test cases
+	testCases := []struct {
+		name              string
+		objectID          string
+		objectStorageFake *fakes.InterfaceObjectStorage
+		expectedStatus    int
+		expectedHeaders   map[string]string
+	}{
+		{
+			name:              "Success",
+			objectID:          "testobject",
+			objectStorageFake: objectStorageSuccess,
+			expectedStatus:    http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Content-Length": "12",
+				"ETag":           `"9a0364b9e99bb480dd25e1f0284c8555"`,
+				"Last-Modified":  "Thu, 02 Jan 2025 03:04:05 GMT",
+			},
+		},
+		{
+			name:              "Invalid ObjectID Characters",
+			objectID:          "test-object!",
+			objectStorageFake: objectStorageFailure1,
+			expectedStatus:    http.StatusBadRequest,
+		},
+		{
+			name:              "Object Not Found",
+			objectID:          "nonexistent",
+			objectStorageFake: objectStorageFailure2,
+			expectedStatus:    http.StatusNotFound,
+		},
+		{
+			name:              "Backend Failure",
+			objectID:          "testobject",
+			objectStorageFake: objectStorageFailure3,
+			expectedStatus:    http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gin router
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.HEAD("/object/:id", HandleHeadObject(tc.objectStorageFake))
+
+			// Create a test request
+			req, _ := http.NewRequest(http.MethodHead, "/object/"+tc.objectID, nil)
+			resp := httptest.NewRecorder()
+
+			// Serve the request
+			router.ServeHTTP(resp, req)
+
+			// Check status code, headers and that no body was sent
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			for key, value := range tc.expectedHeaders {
+				assert.Equal(t, value, resp.Header().Get(key))
+			}
+			assert.Empty(t, resp.Body.String())
+		})
+	}
+}
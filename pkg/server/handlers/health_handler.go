@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+// HandleGetNodeHealth creates a handler for the GET /health/nodes endpoint reporting the health of every node
+func HandleGetNodeHealth(checker objectstorage.HealthChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, BuildResponse("success", "Node health", checker.NodeHealth()))
+	}
+}
+
+// HandleReady creates a handler for the GET /ready endpoint. It answers 503 Service Unavailable
+// with the failing nodes until the storage backend can serve requests. Backends without a
+// readiness check, passed as nil, are always ready.
+func HandleReady(checker objectstorage.ReadinessChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.JSON(http.StatusOK, BuildResponse("success", "Ready", nil))
+			return
+		}
+
+		readiness := checker.Readiness()
+		if !readiness.Ready {
+			c.JSON(http.StatusServiceUnavailable, BuildResponse("error", "Not ready", readiness))
+			return
+		}
+		c.JSON(http.StatusOK, BuildResponse("success", "Ready", readiness))
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage/fakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleGetNodeHealth(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	checker := &fakes.InterfaceHealthChecker{}
+	checker.NodeHealthReturns([]objectstorage.NodeHealth{
+		{ID: "id-1", Name: "node-1", Endpoint: "169.253.0.2:9000", Healthy: true, ConsecutiveSuccesses: 4},
+		{ID: "id-2", Name: "node-2", Endpoint: "169.253.0.3:9000", ConsecutiveFailures: 3, LastError: "liveness check returned 503 Service Unavailable"},
+	})
+
+	router := gin.New()
+	router.GET("/health/nodes", HandleGetNodeHealth(checker))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/health/nodes", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"success","message":"Node health","data":[`+
+		`{"id":"id-1","name":"node-1","endpoint":"169.253.0.2:9000","healthy":true,"consecutive_failures":0,"consecutive_successes":4},`+
+		`{"id":"id-2","name":"node-2","endpoint":"169.253.0.3:9000","healthy":false,"consecutive_failures":3,"consecutive_successes":0,"last_error":"liveness check returned 503 Service Unavailable"}]}`,
+		w.Body.String())
+}
+
+func TestHandleReady(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	ready := &fakes.InterfaceReadinessChecker{}
+	ready.ReadinessReturns(objectstorage.Readiness{Ready: true, ReadyNodes: 3, RequiredNodes: 2})
+
+	notReady := &fakes.InterfaceReadinessChecker{}
+	notReady.ReadinessReturns(objectstorage.Readiness{ReadyNodes: 1, RequiredNodes: 2, FailingNodes: []objectstorage.FailingNode{
+		{Name: "node-2", Reason: "client not initialized"},
+	}})
+
+	// Test cases
+	testCases := []struct {
+		name             string
+		checker          objectstorage.ReadinessChecker
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:             "Ready",
+			checker:          ready,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"status":"success","message":"Ready","data":{"ready":true,"ready_nodes":3,"required_nodes":2}}`,
+		},
+		{
+			name:             "Not Ready",
+			checker:          notReady,
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedResponse: `{"status":"error","message":"Not ready","data":{"ready":false,"ready_nodes":1,"required_nodes":2,"failing_nodes":[{"name":"node-2","reason":"client not initialized"}]}}`,
+		},
+		{
+			name:             "Without Readiness Check",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"status":"success","message":"Ready"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/ready", HandleReady(tc.checker))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/ready", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+// HandleListObjects creates a handler for the GET /object endpoint.
+// Results are paginated with the limit and cursor query parameters.
+func HandleListObjects(storageService objectstorage.ObjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := objectstorage.ListOptions{
+			Prefix: c.Query("prefix"),
+			Cursor: c.Query("cursor"),
+		}
+
+		if limit := c.Query("limit"); limit != "" {
+			parsed, err := strconv.Atoi(limit)
+			if err != nil {
+				abortWithError(c, &ValidationError{message: "limit must be a number"}, "")
+				return
+			}
+			opts.Limit = parsed
+		}
+
+		// List the objects
+		result, err := storageService.ListObjects(c, opts)
+		if err != nil {
+			abortWithError(c, err, "Failed to list objects")
+			return
+		}
+
+		c.JSON(http.StatusOK, BuildResponse("success", "Objects listed successfully", result))
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage/fakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleListObjects(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	modified := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	objectStorageFailure1 := &fakes.InterfaceObjectStorage{}
+	objectStorageFailure1.ListObjectsReturns(nil, fmt.Errorf("%w: invalid cursor", objectstorage.ErrInvalidListOptions))
+
+	objectStorageFailure2 := &fakes.InterfaceObjectStorage{}
+	objectStorageFailure2.ListObjectsReturns(nil, errors.New("failed to list objects: no storage nodes available"))
+
+	objectStorageSuccess := &fakes.InterfaceObjectStorage{}
+	objectStorageSuccess.ListObjectsReturns(&objectstorage.ListResult{
+		Objects:     []objectstorage.ObjectInfo{{ID: "abc", Size: 3, ETag: "etag", LastModified: modified}},
+		NextCursor:  "YWJj",
+		IsTruncated: true,
+		Partial:     true,
+		FailedNodes: []string{"node-2"},
+	}, nil)
+
+	// Test cases
+	testCases := []struct {
+		name              string
+		query             string
+		objectStorageFake *fakes.InterfaceObjectStorage
+		expectedStatus    int
+		expectedResponse  string
+		expectedOptions   *objectstorage.ListOptions
+	}{
+		{
+			name:              "Success",
+			query:             "?prefix=a&limit=1&cursor=abc",
+			objectStorageFake: objectStorageSuccess,
+			expectedStatus:    http.StatusOK,
+			expectedResponse: `{"status":"success","message":"Objects listed successfully","data":{
+				"objects":[{"id":"abc","size":3,"etag":"etag","last_modified":"2025-01-02T03:04:05Z"}],
+				"next_cursor":"YWJj","is_truncated":true,"partial":true,"failed_nodes":["node-2"]}}`,
+			expectedOptions: &objectstorage.ListOptions{Prefix: "a", Limit: 1, Cursor: "abc"},
+		},
+		{
+			name:              "Invalid Limit",
+			query:             "?limit=ten",
+			objectStorageFake: &fakes.InterfaceObjectStorage{},
+			expectedStatus:    http.StatusBadRequest,
+			expectedResponse:  `{"status":"error","code":"invalid_request","message":"limit must be a number"}`,
+		},
+		{
+			name:              "Invalid Cursor",
+			query:             "?cursor=!!",
+			objectStorageFake: objectStorageFailure1,
+			expectedStatus:    http.StatusBadRequest,
+			expectedResponse:  `{"status":"error","code":"invalid_request","message":"invalid list options: invalid cursor"}`,
+		},
+		{
+			name:              "All Nodes Down",
+			objectStorageFake: objectStorageFailure2,
+			expectedStatus:    http.StatusInternalServerError,
+			expectedResponse:  `{"status":"error","code":"internal_error","message":"Failed to list objects"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gin router
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.GET("/object", HandleListObjects(tc.objectStorageFake))
+
+			// Create a test request
+			req, _ := http.NewRequest(http.MethodGet, "/object"+tc.query, nil)
+			resp := httptest.NewRecorder()
+
+			// Serve the request
+			router.ServeHTTP(resp, req)
+
+			// Check status code and body
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			assert.JSONEq(t, tc.expectedResponse, resp.Body.String())
+
+			if tc.expectedOptions != nil {
+				_, opts := tc.objectStorageFake.ListObjectsArgsForCall(0)
+				assert.Equal(t, *tc.expectedOptions, opts)
+			}
+		})
+	}
+}
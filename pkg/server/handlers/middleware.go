@@ -73,7 +73,7 @@ func Recovery(logger *zap.Logger) gin.HandlerFunc {
 				)
 
 				// Respond with error
-				c.JSON(http.StatusInternalServerError, BuildResponse("error", "Internal server error", nil))
+				writeError(c, http.StatusInternalServerError, "internal_error", "Internal server error")
 				c.Abort()
 			}
 		}()
@@ -93,53 +93,14 @@ func GetRequestID(c *gin.Context) string {
 
 // BuildResponse creates a standardized API response
 func BuildResponse(key string, msg string, data interface{}) gin.H {
-	return gin.H{
+	response := gin.H{
 		"status":  key,
 		"message": msg,
-		// "data":    data,
 	}
-}
-
-// ValidationError represents an error that occurs during validation
-type ValidationError struct {
-	message string
-}
-
-func (e *ValidationError) Error() string {
-	return e.message
-}
-
-// validateObjectID ensures the object ID meets the requirements
-func validateObjectID(id string) error {
-	if len(id) == 0 || len(id) > 32 {
-		return &ValidationError{message: "object ID must be between 1 and 32 characters"}
-	}
-
-	for _, char := range id {
-		if !((char >= 'a' && char <= 'z') ||
-			(char >= 'A' && char <= 'Z') ||
-			(char >= '0' && char <= '9')) {
-			return &ValidationError{message: "object ID must contain only alphanumeric characters"}
-		}
-	}
-
-	return nil
-}
-
-// HandleError processes errors and returns appropriate responses
-func HandleError(c *gin.Context, err error) {
-	requestID := GetRequestID(c)
-
-	switch e := err.(type) {
-	case *ValidationError:
-		c.JSON(http.StatusBadRequest, BuildResponse("error", e.Error(), gin.H{
-			"request_id": requestID,
-		}))
-	default:
-		c.JSON(http.StatusInternalServerError, BuildResponse("error", "Internal server error", gin.H{
-			"request_id": requestID,
-		}))
+	if data != nil {
+		response["data"] = data
 	}
+	return response
 }
 
 const ContextLoggerKey = "contextLogger"
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+// userMetadataHeaderPrefix marks request and response headers carrying user metadata
+const userMetadataHeaderPrefix = "X-Object-Meta-"
+
+// defaultContentType is sent for objects stored without a Content-Type
+const defaultContentType = "application/octet-stream"
+
+// putOptionsFromRequest collects the metadata headers of an upload
+func putOptionsFromRequest(c *gin.Context) objectstorage.PutOptions {
+	opts := objectstorage.PutOptions{
+		ContentType:        c.GetHeader("Content-Type"),
+		ContentDisposition: c.GetHeader("Content-Disposition"),
+		CacheControl:       c.GetHeader("Cache-Control"),
+	}
+
+	for key, values := range c.Request.Header {
+		key = http.CanonicalHeaderKey(key)
+		if !strings.HasPrefix(key, userMetadataHeaderPrefix) || len(key) == len(userMetadataHeaderPrefix) || len(values) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(key, userMetadataHeaderPrefix)
+		if opts.UserMetadata == nil {
+			opts.UserMetadata = make(map[string]string)
+		}
+		opts.UserMetadata[name] = values[0]
+	}
+
+	return opts
+}
+
+// setObjectHeaders sets the response headers describing a stored object
+func setObjectHeaders(c *gin.Context, info objectstorage.ObjectInfo) {
+	header := c.Writer.Header()
+
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+	header.Set("Content-Type", contentType)
+
+	if info.ContentDisposition != "" {
+		header.Set("Content-Disposition", info.ContentDisposition)
+	}
+	if info.CacheControl != "" {
+		header.Set("Cache-Control", info.CacheControl)
+	}
+	for key, value := range info.UserMetadata {
+		header.Set(userMetadataHeaderPrefix+key, value)
+	}
+	if info.ETag != "" {
+		header.Set("ETag", `"`+info.ETag+`"`)
+	}
+	if !info.LastModified.IsZero() {
+		header.Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
@@ -16,22 +16,20 @@ func HandlePutObject(storageService objectstorage.ObjectStorage) gin.HandlerFunc
 		// Get the size of the request body
 		contentLength := c.Request.ContentLength
 		if contentLength <= 0 {
-			c.JSON(http.StatusBadRequest, BuildResponse("error", "Content-Length header is required", nil))
+			abortWithError(c, &ValidationError{message: "Content-Length header is required"}, "")
+			return
+		}
+
+		opts := putOptionsFromRequest(c)
+		if err := setPutConditions(c, &opts); err != nil {
+			abortWithError(c, err, "")
 			return
 		}
 
 		// Store the object
-		err := storageService.PutObject(c, objectID, c.Request.Body, contentLength)
+		err := storageService.PutObject(c, objectID, c.Request.Body, contentLength, opts)
 		if err != nil {
-			c.Error(err)
-
-			if fmt.Sprintf("%v", err) == "object ID must contain only alphanumeric characters" ||
-				fmt.Sprintf("%v", err) == "object ID must be between 1 and 32 characters" {
-				c.JSON(http.StatusBadRequest, BuildResponse("error", err.Error(), nil))
-				return
-			}
-
-			c.JSON(http.StatusInternalServerError, BuildResponse("error", "Failed to store object", nil))
+			abortWithError(c, err, "Failed to store object")
 			return
 		}
 
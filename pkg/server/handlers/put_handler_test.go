@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
 	"github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage/fakes"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,14 +21,17 @@ func TestHandlePutbject(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	objectStorageFailure1 := &fakes.InterfaceObjectStorage{}
-	objectStorageFailure1.PutObjectReturns(fmt.Errorf("object ID must contain only alphanumeric characters"))
+	objectStorageFailure1.PutObjectReturns(objectstorage.NewError(objectstorage.ErrInvalidID, "object ID must contain only alphanumeric characters", nil))
 
 	objectStorageFailure2 := &fakes.InterfaceObjectStorage{}
-	objectStorageFailure2.PutObjectReturns(errors.New("object ID must be between 1 and 32 characters"))
+	objectStorageFailure2.PutObjectReturns(objectstorage.NewError(objectstorage.ErrInvalidID, "object ID must be between 1 and 32 characters", nil))
 
 	objectStorageFailure3 := &fakes.InterfaceObjectStorage{}
 	objectStorageFailure3.PutObjectReturns(errors.New("Failed to store object"))
 
+	objectStorageUnavailable := &fakes.InterfaceObjectStorage{}
+	objectStorageUnavailable.PutObjectReturns(fmt.Errorf("quorum not reached: %w", &objectstorage.NodeUnavailableError{Node: "node-1", RetryAfter: 12500 * time.Millisecond}))
+
 	objectStorageSuccess := &fakes.InterfaceObjectStorage{}
 	objectStorageSuccess.PutObjectReturns(nil)
 
@@ -37,6 +43,7 @@ func TestHandlePutbject(t *testing.T) {
 		expectedStatus    int
 		expectedResponse  string
 		checkBody         bool
+		expectedRetry     string
 	}{
 		{
 			name:              "Success",
@@ -51,7 +58,7 @@ func TestHandlePutbject(t *testing.T) {
 			objectID:          "test-object!",
 			objectStorageFake: objectStorageFailure1,
 			expectedStatus:    http.StatusBadRequest,
-			expectedResponse:  `{"status":"error","message":"object ID must contain only alphanumeric characters"}`,
+			expectedResponse:  `{"status":"error","code":"invalid_id","message":"object ID must contain only alphanumeric characters"}`,
 			checkBody:         true,
 		},
 		{
@@ -59,7 +66,7 @@ func TestHandlePutbject(t *testing.T) {
 			objectID:          "testobjectthatiswaytoolongforthelimitsofthesystem",
 			objectStorageFake: objectStorageFailure2,
 			expectedStatus:    http.StatusBadRequest,
-			expectedResponse:  `{"status":"error","message":"object ID must be between 1 and 32 characters"}`,
+			expectedResponse:  `{"status":"error","code":"invalid_id","message":"object ID must be between 1 and 32 characters"}`,
 			checkBody:         true,
 		},
 		{
@@ -67,8 +74,17 @@ func TestHandlePutbject(t *testing.T) {
 			objectID:          "nonexistent",
 			objectStorageFake: objectStorageFailure3,
 			expectedStatus:    http.StatusInternalServerError,
-			expectedResponse:  `{"status":"error","message":"Failed to store object"}`,
+			expectedResponse:  `{"status":"error","code":"internal_error","message":"Failed to store object"}`,
+			checkBody:         true,
+		},
+		{
+			name:              "Node Unavailable",
+			objectID:          "testobject",
+			objectStorageFake: objectStorageUnavailable,
+			expectedStatus:    http.StatusServiceUnavailable,
+			expectedResponse:  `{"status":"error","code":"unavailable","message":"Storage node unavailable"}`,
 			checkBody:         true,
+			expectedRetry:     "13",
 		},
 	}
 
@@ -76,6 +92,7 @@ func TestHandlePutbject(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Create a new Gin router
 			router := gin.New()
+			router.Use(ErrorHandler())
 
 			// Make sure we're using the correct fake for each test case
 			router.PUT("/object/:id", HandlePutObject(tc.objectStorageFake))
@@ -92,6 +109,7 @@ func TestHandlePutbject(t *testing.T) {
 
 			// Check status code
 			assert.Equal(t, tc.expectedStatus, resp.Code)
+			assert.Equal(t, tc.expectedRetry, resp.Header().Get("Retry-After"))
 
 			// Check response body if needed
 			if tc.checkBody {
@@ -106,3 +124,36 @@ func TestHandlePutbject(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlePutObjectMetadata(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	objectStorageFake := &fakes.InterfaceObjectStorage{}
+	objectStorageFake.PutObjectReturns(nil)
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.PUT("/object/:id", HandlePutObject(objectStorageFake))
+
+	req, _ := http.NewRequest(http.MethodPut, "/object/page", strings.NewReader("<html></html>"))
+	req.Header.Set("Content-Type", "text/html")
+	req.Header.Set("Content-Disposition", `inline; filename="page.html"`)
+	req.Header.Set("Cache-Control", "max-age=60")
+	req.Header.Set("X-Object-Meta-Owner", "alice")
+	req.Header.Set("x-object-meta-project", "gateway")
+	req.Header.Set("X-Unrelated", "ignored")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	_, objectID, _, size, opts := objectStorageFake.PutObjectArgsForCall(0)
+	assert.Equal(t, "page", objectID)
+	assert.Equal(t, int64(13), size)
+	assert.Equal(t, objectstorage.PutOptions{
+		ContentType:        "text/html",
+		ContentDisposition: `inline; filename="page.html"`,
+		CacheControl:       "max-age=60",
+		UserMetadata:       map[string]string{"Owner": "alice", "Project": "gateway"},
+	}, opts)
+}
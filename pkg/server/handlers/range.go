@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+)
+
+// errNoOverlap is returned when none of the requested ranges overlap the object
+var errNoOverlap = errors.New("invalid range: failed to overlap")
+
+// parseRange parses a Range header such as "bytes=0-99,-50" against an object of the given size.
+// It returns nil when the header should be ignored and the whole object sent, which includes
+// malformed headers as RFC 7233 asks. Only well-formed ranges the object cannot satisfy are errors.
+func parseRange(header string, size int64) ([]objectstorage.ByteRange, error) {
+	const prefix = "bytes="
+	if header == "" || !strings.HasPrefix(header, prefix) {
+		// Unknown range units are ignored
+		return nil, nil
+	}
+
+	var ranges []objectstorage.ByteRange
+	noOverlap := false
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		startStr, endStr, found := strings.Cut(spec, "-")
+		if !found {
+			return nil, nil
+		}
+		startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+
+		var r objectstorage.ByteRange
+		if startStr == "" {
+			// Suffix range "-N" selects the last N bytes
+			if endStr == "" || endStr[0] == '-' {
+				return nil, nil
+			}
+			n, err := strconv.ParseInt(endStr, 10, 64)
+			if err != nil || n < 0 {
+				return nil, nil
+			}
+			if n == 0 || size == 0 {
+				noOverlap = true
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r = objectstorage.ByteRange{Start: size - n, End: size - 1}
+		} else {
+			start, err := strconv.ParseInt(startStr, 10, 64)
+			if err != nil || start < 0 {
+				return nil, nil
+			}
+			if start >= size {
+				// The range begins past the end of the object
+				noOverlap = true
+				continue
+			}
+			r = objectstorage.ByteRange{Start: start, End: size - 1}
+			if endStr != "" {
+				end, err := strconv.ParseInt(endStr, 10, 64)
+				if err != nil || start > end {
+					return nil, nil
+				}
+				if end < size-1 {
+					r.End = end
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		if noOverlap {
+			return nil, errNoOverlap
+		}
+		return nil, nil
+	}
+
+	// Serve the whole object rather than more bytes than it holds
+	var total int64
+	for _, r := range ranges {
+		total += r.Length()
+	}
+	if total > size {
+		return nil, nil
+	}
+
+	return ranges, nil
+}
+
+// contentRange formats the Content-Range header value for a range of an object
+func contentRange(r objectstorage.ByteRange, size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.End, size)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"testing"
+
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRange(t *testing.T) {
+	testCases := []struct {
+		name           string
+		header         string
+		size           int64
+		expectedRanges []objectstorage.ByteRange
+		expectedErr    error
+	}{
+		{name: "No Header", header: "", size: 10},
+		{name: "Unknown Unit", header: "items=0-1", size: 10},
+		{name: "Closed Range", header: "bytes=2-5", size: 10, expectedRanges: []objectstorage.ByteRange{{Start: 2, End: 5}}},
+		{name: "Open Range", header: "bytes=7-", size: 10, expectedRanges: []objectstorage.ByteRange{{Start: 7, End: 9}}},
+		{name: "Suffix Range", header: "bytes=-3", size: 10, expectedRanges: []objectstorage.ByteRange{{Start: 7, End: 9}}},
+		{name: "Suffix Longer Than Object", header: "bytes=-30", size: 10, expectedRanges: []objectstorage.ByteRange{{Start: 0, End: 9}}},
+		{name: "End Clamped To Size", header: "bytes=5-100", size: 10, expectedRanges: []objectstorage.ByteRange{{Start: 5, End: 9}}},
+		{
+			name:           "Multiple Ranges",
+			header:         "bytes=0-1, 4-5,-2",
+			size:           10,
+			expectedRanges: []objectstorage.ByteRange{{Start: 0, End: 1}, {Start: 4, End: 5}, {Start: 8, End: 9}},
+		},
+		{name: "Skips Ranges Past End", header: "bytes=20-30,0-0", size: 10, expectedRanges: []objectstorage.ByteRange{{Start: 0, End: 0}}},
+		{name: "Overlapping Ranges Larger Than Object", header: "bytes=0-9,0-9", size: 10},
+		{name: "Start Past End", header: "bytes=10-", size: 10, expectedErr: errNoOverlap},
+		{name: "Zero Suffix", header: "bytes=-0", size: 10, expectedErr: errNoOverlap},
+		{name: "Empty Object", header: "bytes=0-", size: 0, expectedErr: errNoOverlap},
+		{name: "Reversed Range", header: "bytes=5-2", size: 10},
+		{name: "Not A Number", header: "bytes=a-b", size: 10},
+		{name: "Missing Dash", header: "bytes=5", size: 10},
+		{name: "Empty Set", header: "bytes=", size: 10},
+		{name: "Malformed Among Valid", header: "bytes=0-1,x-2", size: 10},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ranges, err := parseRange(tc.header, tc.size)
+
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedRanges, ranges)
+		})
+	}
+}
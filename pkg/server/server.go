@@ -18,17 +18,19 @@ import (
 
 // Server encapsulates the HTTP server and its dependencies
 type App struct {
-	port        string
-	logger      *zap.Logger
-	storageType string
+	port          string
+	logger        *zap.Logger
+	storageType   string
+	storageConfig objectstorage.Config
 }
 
 // New creates a new server instance
-func New(port string, storageType string, logger *zap.Logger) *App {
+func New(port string, storageType string, storageConfig objectstorage.Config, logger *zap.Logger) *App {
 	return &App{
-		port:        port,
-		logger:      logger,
-		storageType: storageType,
+		port:          port,
+		logger:        logger,
+		storageType:   storageType,
+		storageConfig: storageConfig,
 	}
 }
 
@@ -86,24 +88,52 @@ func (s *App) setupRouter() *gin.Engine {
 	router.Use(handlers.SetRequestID())
 	router.Use(handlers.WithLogger(s.logger))
 	router.Use(handlers.Logger(s.logger))
+	router.Use(handlers.ErrorHandler())
 	router.Use(handlers.Recovery(s.logger))
 
 	objectStorageFactory := objectstorage.NewObjectStorageFactory()
-	storageService := objectStorageFactory.GetObjectStorage(s.storageType, s.logger)
+	storageService := objectStorageFactory.GetObjectStorage(s.storageType, s.storageConfig, s.logger)
 
-	// Health check endpoint
+	// Health check endpoint, a pure liveness check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, handlers.BuildResponse("health", "OK", nil))
 	})
 
+	// Readiness check, fails until the storage backend can serve requests
+	var readiness objectstorage.ReadinessChecker
+	if checker, ok := objectstorage.Find[objectstorage.ReadinessChecker](storageService); ok {
+		readiness = checker
+	}
+	router.GET("/ready", handlers.HandleReady(readiness))
+
+	// Health of the individual nodes, only available when the storage backend checks it
+	if provider, ok := objectstorage.Find[objectstorage.HealthCheckerProvider](storageService); ok {
+		router.GET("/health/nodes", handlers.HandleGetNodeHealth(provider.HealthChecker()))
+	}
+
 	// API group with version
 	v1 := router.Group("/api/v1")
 	{
 		// Objects API
 		objects := v1.Group("/object")
 		{
+			objects.GET("", handlers.HandleListObjects(storageService))
 			objects.GET("/:id", handlers.HandleGetObject(storageService))
+			objects.HEAD("/:id", handlers.HandleHeadObject(storageService))
 			objects.PUT("/:id", handlers.HandlePutObject(storageService))
+			objects.DELETE("/:id", handlers.HandleDeleteObject(storageService))
+		}
+	}
+
+	// Admin API, only available when the storage backend supports it
+	if provider, ok := objectstorage.Find[objectstorage.RebalancerProvider](storageService); ok {
+		rebalancer := provider.Rebalancer()
+		admin := router.Group("/admin")
+		{
+			admin.GET("/rebalance", handlers.HandleGetRebalance(rebalancer))
+			admin.POST("/rebalance", handlers.HandleStartRebalance(rebalancer))
+			admin.DELETE("/rebalance", handlers.HandleStopRebalance(rebalancer))
+			admin.PUT("/rebalance/throttle", handlers.HandleSetRebalanceThrottle(rebalancer))
 		}
 	}
 
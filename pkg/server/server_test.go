@@ -0,0 +1,196 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	objectstorage "github.com/singhmeghna79/homework-object-storage/pkg/internals/objectStorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRouterWithMemoryStorage(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	router := New("3000", "memory", objectstorage.DefaultConfig(), zap.NewNop()).setupRouter()
+
+	// The steps run in order against the same storage
+	testCases := []struct {
+		name             string
+		method           string
+		path             string
+		body             string
+		headers          map[string]string
+		expectedStatus   int
+		expectedResponse string
+		expectedHeaders  map[string]string
+	}{
+		{
+			name:             "Ready Without Nodes",
+			method:           http.MethodGet,
+			path:             "/ready",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"status":"success","message":"Ready"}`,
+		},
+		{
+			name:           "No Node Health",
+			method:         http.MethodGet,
+			path:           "/health/nodes",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:             "Missing Object",
+			method:           http.MethodGet,
+			path:             "/api/v1/object/test123",
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"status":"error","code":"not_found","message":"Object not found","request_id":"req-1"}`,
+			expectedHeaders:  map[string]string{"X-Request-ID": "req-1"},
+		},
+		{
+			name:             "Store Object",
+			method:           http.MethodPut,
+			path:             "/api/v1/object/test123",
+			body:             "This is a test object",
+			headers:          map[string]string{"Content-Type": "text/plain", "X-Object-Meta-Owner": "sync"},
+			expectedStatus:   http.StatusCreated,
+			expectedResponse: `{"status":"success","message":"Object test123 stored successfully"}`,
+		},
+		{
+			name:             "Retrieve Object",
+			method:           http.MethodGet,
+			path:             "/api/v1/object/test123",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: "This is a test object",
+			expectedHeaders:  map[string]string{"Content-Type": "text/plain", "X-Object-Meta-Owner": "sync"},
+		},
+		{
+			name:             "Retrieve Range",
+			method:           http.MethodGet,
+			path:             "/api/v1/object/test123",
+			headers:          map[string]string{"Range": "bytes=10-13"},
+			expectedStatus:   http.StatusPartialContent,
+			expectedResponse: "test",
+			expectedHeaders:  map[string]string{"Content-Range": "bytes 10-13/21"},
+		},
+		{
+			name:            "Object Info",
+			method:          http.MethodHead,
+			path:            "/api/v1/object/test123",
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"Content-Length": "21"},
+		},
+		{
+			name:             "Create Only Conflict",
+			method:           http.MethodPut,
+			path:             "/api/v1/object/test123",
+			body:             "other",
+			headers:          map[string]string{"If-None-Match": "*"},
+			expectedStatus:   http.StatusPreconditionFailed,
+			expectedResponse: `{"status":"error","code":"precondition_failed","message":"Precondition failed","request_id":"req-1"}`,
+		},
+		{
+			name:             "List Objects",
+			method:           http.MethodGet,
+			path:             "/api/v1/object?prefix=test",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `"objects":[{"id":"test123","size":21`,
+		},
+		{
+			name:             "Invalid Object ID",
+			method:           http.MethodDelete,
+			path:             "/api/v1/object/test-123",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"status":"error","code":"invalid_id","message":"object ID must contain only alphanumeric characters","request_id":"req-1"}`,
+		},
+		{
+			name:             "Delete Object",
+			method:           http.MethodDelete,
+			path:             "/api/v1/object/test123",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"status":"success","message":"Object test123 deleted successfully"}`,
+		},
+		{
+			name:           "Deleted Object",
+			method:         http.MethodHead,
+			path:           "/api/v1/object/test123",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("X-Request-ID", "req-1")
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			for key, value := range tc.expectedHeaders {
+				assert.Equal(t, value, resp.Header().Get(key))
+			}
+
+			switch {
+			case tc.expectedResponse == "":
+			case strings.HasPrefix(tc.expectedResponse, "{"):
+				assert.JSONEq(t, tc.expectedResponse, resp.Body.String())
+			default:
+				assert.Contains(t, resp.Body.String(), tc.expectedResponse)
+			}
+		})
+	}
+}
+
+func TestRouterWithEncryption(t *testing.T) {
+	// Set Gin to Test Mode
+	gin.SetMode(gin.TestMode)
+
+	config := objectstorage.DefaultConfig()
+	config.EncryptionKeyFile = filepath.Join(t.TempDir(), "keys.yaml")
+	keyFile := "current: k1\nkeys:\n  k1: MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n"
+	require.NoError(t, os.WriteFile(config.EncryptionKeyFile, []byte(keyFile), 0o600))
+	router := New("3000", "memory", config, zap.NewNop()).setupRouter()
+
+	send := func(method string, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/api/v1/object/test123", strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send(http.MethodPut, "This is a test object", map[string]string{"X-Object-Meta-Owner": "sync"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	// The handlers see the decrypted object, the encryption metadata is not sent
+	resp = send(http.MethodHead, "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "21", resp.Header().Get("Content-Length"))
+	assert.Equal(t, "sync", resp.Header().Get("X-Object-Meta-Owner"))
+	assert.Empty(t, resp.Header().Get("X-Object-Meta-Encryption-Key"))
+
+	resp = send(http.MethodGet, "", map[string]string{"Range": "bytes=10-13"})
+	assert.Equal(t, http.StatusPartialContent, resp.Code)
+	assert.Equal(t, "bytes 10-13/21", resp.Header().Get("Content-Range"))
+	assert.Equal(t, "test", resp.Body.String())
+
+	resp = send(http.MethodGet, "", map[string]string{"Range": "bytes=0-3,-6"})
+	assert.Equal(t, http.StatusPartialContent, resp.Code)
+	assert.Contains(t, resp.Body.String(), "This")
+	assert.Contains(t, resp.Body.String(), "object")
+
+	resp = send(http.MethodGet, "", nil)
+	assert.Equal(t, "This is a test object", resp.Body.String())
+}